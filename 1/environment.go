@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Environment describes the machine and the binary a StatResult was produced by
+type Environment struct {
+	GoVersion  string
+	GOOS       string
+	GOARCH     string
+	GOMAXPROCS int
+	CpuModel   string `json:",omitempty"`
+	CpuCores   int    `json:",omitempty"`
+	Hostname   string `json:",omitempty"`
+	Timestamp  time.Time
+	GitCommit  string `json:",omitempty"`
+	Seed       int64
+}
+
+func collectEnvironment() Environment {
+	env := Environment{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Timestamp:  time.Now(),
+		Seed:       seed,
+	}
+
+	env.CpuModel, env.CpuCores = readCpuInfo()
+	env.Hostname, _ = os.Hostname()
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				env.GitCommit = setting.Value
+			}
+		}
+	}
+
+	return env
+}
+
+// readCpuInfo returns the model name and the number of logical cores
+// listed in /proc/cpuinfo, which is available only on linux
+func readCpuInfo() (string, int) {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return "", 0
+	}
+	defer f.Close()
+
+	model := ""
+	cores := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		split := strings.SplitN(scanner.Text(), ":", 2)
+		if len(split) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+		switch key {
+		case "processor":
+			cores++
+		case "model name":
+			model = value
+		}
+	}
+
+	return model, cores
+}
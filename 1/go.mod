@@ -1,5 +1,10 @@
 module parallel-computations
 
-go 1.17
+go 1.18
 
-require github.com/Arafatk/glot v0.0.0-20180312013246-79d5219000f0 // indirect
+require github.com/Arafatk/glot v0.0.0-20180312013246-79d5219000f0
+
+require (
+	golang.org/x/image v0.18.0
+	golang.org/x/text v0.16.0 // indirect
+)
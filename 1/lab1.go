@@ -1,50 +1,112 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"math/rand"
 	"os"
+	"parallel-computations/matrix"
+	"runtime"
 	"strings"
-	"sync"
 	"time"
 )
 
 var (
 	matrixSize    int
+	matrixRows    int
+	matrixCols    int
+	elementType   string
 	printMatrices bool
+	verify        bool
 	concurrent    bool
 	threadCount   uint
+	threadsSweep  []uint
+	operationName string
+	scalar        int
+	tileSize      int
+	chunkSize     int
+	strategies    []matrix.DistributionStrategy
+	usePool       bool
 
 	statConfiguration     bool
-	statStart             uint
-	statEnd               uint
-	statStep              uint
+	statSizes             SizeSequence
 	statIterationsPerStep uint
+	statWarmup            uint
+	statCompareDispatch   bool
+	statMemory            bool
+	statComparePool       bool
+	statAdaptive          bool
+	statCITarget          float64
+	statSizeBudget        time.Duration
+	statBudget            time.Duration
 	outputPath            string
+	outputFormat          string
+
+	cpuProfilePath string
+	memProfilePath string
+	tracePath      string
 
 	timePrecision func(time.Duration) int64
+	timeUnit      time.Duration
 	timeSuffix    string
 
-	forEachMatrixElementIndex func(func(int, int))
+	config matrix.Config
+	// thread count -> pool
+	pools = make(map[uint]*matrix.Pool)
 
+	seed  int64
 	_rand rand.Rand
 )
 
+var elementTypeNames = []string{"int", "int64", "float64"}
+
 func init() {
 	// Parsing configuration
 	flag.BoolVar(&printMatrices, "p", false, "print input matrices (works only in runOnce mode)")
+	flag.BoolVar(&verify, "verify", false, "compare results against a sequential computation (in stat mode every size is verified)")
 	uintMatrixSize := flag.Uint("size", 3, "size of the input matrices (works only in runOnce mode)")
+	flag.IntVar(&matrixRows, "rows", 0, "number of rows of the first input matrix (defaults to -size, works only in runOnce mode)")
+	flag.IntVar(&matrixCols, "cols", 0, "number of columns of the first input matrix (defaults to -size, works only in runOnce mode)")
+	flag.StringVar(&elementType, "type", "int", "matrix element type ("+strings.Join(elementTypeNames, "|")+")")
 	flag.BoolVar(&concurrent, "concurrent", false, "run operations concurrently")
 	flag.UintVar(&threadCount, "threads", 0, "number of threads excluding a producer thread")
+	threadsSweepString := flag.String("threads-sweep", "", "comma-delimited thread counts or 'auto' to stat in a single run "+
+		"alongside a sequential baseline (works only in stat mode)")
+	flag.StringVar(&operationName, "op", "add", "operation to run ("+strings.Join(matrix.OperationNames(), "|")+")")
+	flag.IntVar(&scalar, "scalar", 2, "scalar to multiply by (works only with -op scalar)")
+	flag.IntVar(&tileSize, "tile", 64, "tile size for tiled operations")
+	strategyNames := flag.String("strategy", "channel", "work distribution strategy ("+strings.Join(matrix.StrategyNames, "|")+"), "+
+		"stat mode accepts a comma-delimited list or 'all' to compare them")
+	flag.IntVar(&chunkSize, "chunk", 16, "number of rows per message for the chunked-channel strategy")
+	flag.BoolVar(&usePool, "pool", false, "reuse a persistent pool of goroutines instead of starting them on every operation")
 
 	flag.BoolVar(&statConfiguration, "stat", false, "run in stat mode and output statistics as json")
-	flag.UintVar(&statStart, "stat-start", 100, "where to start statting")
-	flag.UintVar(&statEnd, "stat-end", 1000, "where to stop statting")
-	flag.UintVar(&statStep, "stat-step", 100, "statting step")
+	flag.IntVar(&statSizes.Start, "stat-start", 100, "where to start statting")
+	flag.IntVar(&statSizes.End, "stat-end", 1000, "where to stop statting")
+	flag.IntVar(&statSizes.Step, "stat-step", 100, "statting step (works only with linear scale)")
+	flag.StringVar(&statSizes.Scale, "stat-scale", "linear", "how sizes grow from -stat-start to -stat-end ("+strings.Join(sizeScales, "|")+"), "+
+		"pow2pm1 means 2^k-1, 2^k and 2^k+1 for every power of two")
+	flag.Float64Var(&statSizes.Factor, "stat-factor", 2, "statting factor (works only with log scale)")
+	sizesString := flag.String("sizes", "", "comma-delimited matrix sizes to stat instead of a generated sequence")
 	flag.UintVar(&statIterationsPerStep, "iterations", 5, "how many iterations to do each step")
+	flag.UintVar(&statWarmup, "warmup", 0, "how many warm-up iterations to do each step before timing")
+	flag.BoolVar(&statCompareDispatch, "compare-dispatch", false, "stat both row and tile dispatch of matrix multiplication")
+	flag.BoolVar(&statMemory, "memstats", false, "record allocations and GC pauses for every matrix size")
+	flag.BoolVar(&statComparePool, "compare-pool", false, "stat both pooled and spawn-per-call runs")
+	flag.BoolVar(&statAdaptive, "adaptive", false, "repeat every size until the confidence interval is narrow enough "+
+		"or -size-budget is spent, -iterations is the minimum number of iterations")
+	flag.Float64Var(&statCITarget, "ci-target", 0.05, "target half-width of the 95% confidence interval relative to the mean (works only with -adaptive)")
+	flag.DurationVar(&statSizeBudget, "size-budget", 5*time.Second, "time budget for every size (works only with -adaptive)")
+	flag.DurationVar(&statBudget, "budget", 0, "time budget for the whole sweep, after which it stops and writes what was measured (0 means no budget)")
 	flag.StringVar(&outputPath, "o", "", "stat output file location (is constructed from parameters if not specified)")
+	flag.StringVar(&outputFormat, "format", "json", "stat output format ("+strings.Join(outputFormats, "|")+"), "+
+		"ndjson and csv are written as entries are measured")
+
+	flag.StringVar(&cpuProfilePath, "cpuprofile", "", "write a cpu profile to this file")
+	flag.StringVar(&memProfilePath, "memprofile", "", "write a heap profile to this file when finished")
+	flag.StringVar(&tracePath, "trace", "", "write an execution trace to this file")
+
+	flag.Int64Var(&seed, "seed", 0, "seed for random number generators (taken from current time if 0)")
 
 	outputNanoseconds := flag.Bool("nano", false, "output time in nanoseconds (default is milliseconds)")
 
@@ -60,12 +122,106 @@ func init() {
 
 	// General configurations
 	matrixSize = int(*uintMatrixSize)
+	if matrixRows <= 0 {
+		matrixRows = matrixSize
+	}
+	if matrixCols <= 0 {
+		matrixCols = matrixSize
+	}
+
+	validElementType := false
+	for _, name := range elementTypeNames {
+		validElementType = validElementType || name == elementType
+	}
+	if !validElementType {
+		fmt.Printf("unknown element type '%s', expected one of %s\n", elementType, strings.Join(elementTypeNames, "|"))
+		os.Exit(1)
+	}
+
+	validOutputFormat := false
+	for _, format := range outputFormats {
+		validOutputFormat = validOutputFormat || format == outputFormat
+	}
+	if !validOutputFormat {
+		fmt.Printf("unknown output format '%s', expected one of %s\n", outputFormat, strings.Join(outputFormats, "|"))
+		os.Exit(1)
+	}
+
+	// Element type doesn't affect operation's properties
+	operation, err := matrix.FindOperation(operationName, scalar)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if tileSize <= 0 {
+		fmt.Println("tile size has to be positive")
+		os.Exit(1)
+	}
+
+	if chunkSize <= 0 {
+		fmt.Println("chunk size has to be positive")
+		os.Exit(1)
+	}
+
+	strategies, err = matrix.ParseStrategies(*strategyNames, chunkSize)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(strategies) > 1 && !statConfiguration {
+		fmt.Println("several strategies can only be compared in stat mode")
+		os.Exit(1)
+	}
+
+	if statComparePool && !statConfiguration {
+		fmt.Println("pooled and spawn-per-call runs can only be compared in stat mode")
+		os.Exit(1)
+	}
+
+	// adaptive runs do at least 2 iterations regardless of the minimum
+	if statConfiguration && !statAdaptive && statIterationsPerStep < 1 {
+		fmt.Println("stat mode needs at least 1 iteration per size")
+		os.Exit(1)
+	}
+
+	if len(*sizesString) > 0 {
+		statSizes.Sizes, err = parseSizes(*sizesString)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	err = statSizes.Validate()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if len(*threadsSweepString) > 0 {
+		if !statConfiguration {
+			fmt.Println("threads can only be swept in stat mode")
+			os.Exit(1)
+		}
+		threadsSweep, err = parseThreadsSweep(*threadsSweepString)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if statCompareDispatch && operation.Name != "mul" && operation.Name != "mul-tiled" {
+		fmt.Println("dispatch can only be compared for mul and mul-tiled operations")
+		os.Exit(1)
+	}
 
 	if *outputNanoseconds {
 		timePrecision = func(d time.Duration) int64 { return d.Nanoseconds() }
+		timeUnit = time.Nanosecond
 		timeSuffix = "ns"
 	} else {
 		timePrecision = func(d time.Duration) int64 { return d.Milliseconds() }
+		timeUnit = time.Millisecond
 		timeSuffix = "ms"
 	}
 
@@ -79,98 +235,89 @@ func init() {
 		concurrent = true
 	}
 
-	if concurrent {
-		forEachMatrixElementIndex = func(f func(int, int)) {
-			forEachEmbeddedIterationConcurrent(matrixSize, matrixSize, f)
-		}
-	} else {
-		forEachMatrixElementIndex = func(f func(int, int)) {
-			forEachEmbeddedIteration(matrixSize, matrixSize, f)
-		}
+	// sequential runs don't use strategies, the comparison would be silently skipped
+	if len(strategies) > 1 && !concurrent && len(threadsSweep) == 0 {
+		fmt.Println("several strategies can only be compared with -threads or -threads-sweep")
+		os.Exit(1)
 	}
+	if statComparePool && !concurrent && len(threadsSweep) == 0 {
+		fmt.Println("pooled and spawn-per-call runs can only be compared with -threads or -threads-sweep")
+		os.Exit(1)
+	}
+
+	configureIteration()
 
 	// setting rand seed
-	randSource := rand.NewSource(time.Now().UnixNano())
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	randSource := rand.NewSource(seed)
 	_rand = *rand.New(randSource)
 
 	// Summary
-	fmt.Printf("matrix size: %dx%d\n", matrixSize, matrixSize)
+	fmt.Printf("matrix size: %dx%d\n", matrixRows, matrixCols)
+	fmt.Println("element type:", elementType)
+	fmt.Println("operation:", operation.Name)
+	if operation.Tiled || statCompareDispatch {
+		fmt.Printf("tile size: %dx%d\n", tileSize, tileSize)
+	}
 	fmt.Println("time is in", timeSuffix)
+	fmt.Println("seed:", seed)
 
-	if concurrent {
+	if len(threadsSweep) > 0 {
+		fmt.Println("thread counts:", threadsSweep, "and a sequential baseline")
+	} else if concurrent {
 		fmt.Println("thread count:", threadCount)
+		if usePool {
+			fmt.Println("goroutines are pooled")
+		}
 	} else {
 		fmt.Println("operations will be run sequentially")
 	}
 
-	fmt.Println()
-}
-
-func makeSquareMatrix(size int) *[][]int {
-	mat := make([][]int, size)
-	for i := 0; i < size; i++ {
-		mat[i] = make([]int, size)
+	if concurrent || len(threadsSweep) > 0 {
+		fmt.Print("strategy:")
+		for _, s := range strategies {
+			fmt.Print(" ", s.Name())
+		}
+		fmt.Println()
 	}
 
-	return &mat
+	fmt.Println()
 }
 
-func forEachEmbeddedIteration(iMax, jMax int, f func(int, int)) {
-	for i := 0; i < iMax; i++ {
-		for j := 0; j < jMax; j++ {
-			f(i, j)
-		}
+func configureIteration() {
+	config = matrix.Config{
+		Strategy: strategies[0],
+		TileSize: tileSize,
 	}
-}
-
-func forEachEmbeddedIterationConcurrent(iMax, jMax int, f func(int, int)) {
-	channel := make(chan int)
-
-	var wg sync.WaitGroup
-	wg.Add(int(threadCount))
-
-	worker := func() {
-		defer wg.Done()
-		for {
-			i, moreWork := <-channel
-			if !moreWork {
-				return
-			}
-			for j := 0; j < jMax; j++ {
-				f(i, j)
-			}
+	if concurrent {
+		config.Threads = int(threadCount)
+		if usePool {
+			config.Pool = poolFor(threadCount)
 		}
 	}
+}
 
-	for i := 0; i < int(threadCount); i++ {
-		go worker()
-	}
-
-	for i := 0; i < iMax; i++ {
-		channel <- i
+// poolFor creates a pool for every thread count once and reuses it afterwards
+func poolFor(threads uint) *matrix.Pool {
+	pool, ok := pools[threads]
+	if !ok {
+		pool = matrix.NewPool(int(threads))
+		pools[threads] = pool
 	}
-	close(channel)
-
-	wg.Wait()
+	return pool
 }
 
-func fillMatrix(m *[][]int) {
+func fillMatrix[T matrix.Element](m *matrix.Matrix[T]) {
 	kindaSeed := _rand.Intn(100)
-	forEachMatrixElementIndex(
+	config.ForEachIndex(m.Rows, m.Cols,
 		func(i, j int) {
-			(*m)[i][j] = kindaSeed * i * j
+			m.Set(i, j, T(kindaSeed*i*j))
 		},
 	)
 }
 
-func addMatrices(m1, m2 *[][]int) *[][]int {
-	res := makeSquareMatrix(matrixSize)
-	forEachMatrixElementIndex(
-		func(i, j int) { (*res)[i][j] = (*m1)[i][j] + (*m2)[i][j] },
-	)
-	return res
-}
-
 func timeFunction(f func(), iterations int) int64 {
 	start := time.Now()
 	for i := 0; i < iterations; i++ {
@@ -181,54 +328,224 @@ func timeFunction(f func(), iterations int) int64 {
 	return timePrecision(elapsed) / int64(iterations)
 }
 
-func runOnce() {
+func runOnce[T matrix.Element]() error {
 	fmt.Println("running once")
 	fmt.Println("creating matrices")
 
-	m1 := makeSquareMatrix(matrixSize)
-	m2 := makeSquareMatrix(matrixSize)
+	operation, _ := matrix.FindOperation(operationName, T(scalar))
+
+	m1 := matrix.NewMatrix[T](matrixRows, matrixCols)
+	m2 := operation.SecondArgument(m1)
 	fillMatrix(m1)
 	fillMatrix(m2)
 
-	var res *[][]int
-	time := timeFunction(func() { res = addMatrices(m1, m2) }, 1)
+	var res *matrix.Matrix[T]
+	time := timeFunction(func() { res = operation.Apply(config, m1, m2) }, 1)
 
 	fmt.Printf("Elapsed %d%s\n", time, timeSuffix)
 
 	if printMatrices {
-		fmt.Println("m1:   ", m1)
-		fmt.Println("m2:   ", m2)
-		fmt.Println("m1+m2:", res)
+		fmt.Println("m1:    ", m1)
+		if !operation.Unary {
+			fmt.Println("m2:    ", m2)
+		}
+		fmt.Println("result:", res)
 	}
+
+	if verify {
+		err := matrix.Compare(matrix.SequentialReference(operation, m1, m2), res)
+		if err != nil {
+			return fmt.Errorf("verification failed: %w", err)
+		}
+		fmt.Println("verification passed")
+	}
+	return nil
 }
 
+// Time is the average time per iteration, Samples are the times
+// of every iteration and Summary is computed from them.
+// Verified is set when the result was checked against a sequential computation
+// and Memory when memory statistics were recorded
 type Entry struct {
-	Size int
-	Time int
+	Size     int
+	Time     int
+	Samples  []float64
+	Verified bool         `json:",omitempty"`
+	Memory   *MemoryStats `json:",omitempty"`
+	Summary
 }
 
-type StatResult struct {
-	Command string
+type Series struct {
+	Name    string
 	Entries []Entry
 }
 
-func stat() {
+// Entries is set when a single operation is statted,
+// Series is set when several of them are compared.
+// Derived holds speedup and efficiency series of a threads sweep.
+// TimeUnit is the unit of every time, ms or ns
+type StatResult struct {
+	Command     string
+	Environment Environment
+	TimeUnit    string
+	Entries     []Entry         `json:",omitempty"`
+	Series      []Series        `json:",omitempty"`
+	Derived     []DerivedSeries `json:",omitempty"`
+}
+
+// threads of 0 means a sequential run
+type statTarget[T matrix.Element] struct {
+	matrix.Operation[T]
+	strategy matrix.DistributionStrategy
+	threads  uint
+	pooled   bool
+}
+
+func (t statTarget[T]) seriesName() string {
+	name := t.Name
+	if t.Tiled {
+		name += fmt.Sprintf(" %dx%d", tileSize, tileSize)
+	} else if t.threads > 0 && len(strategies) > 1 {
+		name += " " + t.strategy.Name()
+	}
+	if t.threads > 0 && statComparePool {
+		if t.pooled {
+			name += " pool"
+		} else {
+			name += " spawn"
+		}
+	}
+	if len(threadsSweep) > 0 {
+		if t.threads > 0 {
+			name += fmt.Sprintf(" threads=%d", t.threads)
+		} else {
+			name += " sequential"
+		}
+	}
+	return name
+}
+
+func (t statTarget[T]) config() matrix.Config {
+	c := matrix.Config{
+		Threads:  int(t.threads),
+		Strategy: t.strategy,
+		TileSize: tileSize,
+	}
+	if t.pooled {
+		c.Pool = poolFor(t.threads)
+	}
+	return c
+}
+
+// Tiled operations and sequential runs don't depend on the strategy,
+// so they are statted only once
+func statTargets[T matrix.Element](ops []matrix.Operation[T]) []statTarget[T] {
+	threadCounts := []uint{threadCount}
+	if len(threadsSweep) > 0 {
+		threadCounts = append([]uint{0}, threadsSweep...)
+	}
+
+	pooled := []bool{usePool}
+	if statComparePool {
+		pooled = []bool{false, true}
+	}
+
+	targets := make([]statTarget[T], 0, len(ops)*len(strategies)*len(threadCounts)*len(pooled))
+	for _, op := range ops {
+		for _, threads := range threadCounts {
+			if threads == 0 {
+				targets = append(targets, statTarget[T]{op, strategies[0], threads, false})
+				continue
+			}
+			opStrategies := strategies
+			if op.Tiled {
+				opStrategies = strategies[:1]
+			}
+			for _, s := range opStrategies {
+				for _, p := range pooled {
+					targets = append(targets, statTarget[T]{op, s, threads, p})
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// stat returns errors instead of exiting, so that profiling is stopped
+// and the profiles of failed runs are complete
+func stat[T matrix.Element]() error {
 	fmt.Println("running in stat mode")
-	fmt.Printf("statting for matrix sizes from %d to %d with a step size of %d\n",
-		statStart,
-		statEnd,
-		statStep,
-	)
+	fmt.Println("output format:", outputFormat)
+	sizes := statSizes.Generate()
+	fmt.Println("statting for matrix sizes", sizes)
 	fmt.Printf("will be doing %d iterations per matrix size\n", statIterationsPerStep)
+	if statWarmup > 0 {
+		fmt.Printf("preceded by %d warm-up iterations\n", statWarmup)
+	}
+	if verify {
+		fmt.Println("results will be verified for every matrix size")
+	}
+	if statMemory {
+		fmt.Println("memory statistics will be recorded for every matrix size")
+	}
+	if statAdaptive {
+		fmt.Printf("sizes will be repeated until the confidence interval is within %.1f%% of the mean or %v is spent\n",
+			statCITarget*100, statSizeBudget)
+	}
+
+	var deadline time.Time
+	if statBudget > 0 {
+		deadline = time.Now().Add(statBudget)
+		fmt.Println("the sweep will stop after", statBudget)
+	}
+
+	operations := matrix.Operations(T(scalar))
+	operation := operations[operationName]
+	operationsToStat := []matrix.Operation[T]{operation}
+	if statCompareDispatch {
+		operationsToStat = []matrix.Operation[T]{operations["mul"], operations["mul-tiled"]}
+	}
+	targets := statTargets(operationsToStat)
 
 	if len(outputPath) == 0 {
-		outputPath = "stat_"
-		if concurrent {
+		outputPath = fmt.Sprintf("stat_op=%s_", operation.Name)
+		if statCompareDispatch {
+			outputPath = "stat_op=mul_dispatch=row+tile_"
+		}
+		outputPath += fmt.Sprintf("type=%s_", elementType)
+		if operation.Tiled || statCompareDispatch {
+			outputPath += fmt.Sprintf("tile=%d_", tileSize)
+		}
+		if len(threadsSweep) > 0 {
+			outputPath += "threads="
+			for i, t := range threadsSweep {
+				if i > 0 {
+					outputPath += "+"
+				}
+				outputPath += fmt.Sprint(t)
+			}
+			outputPath += "_"
+		} else if concurrent {
 			outputPath += fmt.Sprintf("threads=%d_", threadCount)
 		} else {
 			outputPath += "sequential_"
 		}
-		outputPath += fmt.Sprintf("from=%d_to=%d_step=%d_iterations=%d.json", statStart, statEnd, statStep, statIterationsPerStep)
+		if statComparePool {
+			outputPath += "pool+spawn_"
+		} else if usePool {
+			outputPath += "pool_"
+		}
+		if concurrent || len(threadsSweep) > 0 {
+			outputPath += "strategy="
+			for i, s := range strategies {
+				if i > 0 {
+					outputPath += "+"
+				}
+				outputPath += s.Name()
+			}
+			outputPath += "_"
+		}
+		outputPath += fmt.Sprintf("%v_iterations=%d.%s", statSizes, statIterationsPerStep, outputFormat)
 	}
 
 	fmt.Println("output will be written to", outputPath)
@@ -237,57 +554,177 @@ func stat() {
 
 	f, err := os.Create(outputPath)
 	if err != nil {
-		fmt.Println("couldn't create a file at", outputPath)
-		fmt.Println(err)
-		os.Exit(1)
+		return fmt.Errorf("couldn't create a file at %s: %w", outputPath, err)
+	}
+	defer f.Close()
+
+	command := strings.Join(os.Args, " ")
+	environment := collectEnvironment()
+	writer, err := newStatWriter(outputFormat, f, command, environment, timeSuffix)
+	if err != nil {
+		return fmt.Errorf("error writing to %s: %w", outputPath, err)
+	}
+
+	series := make([]Series, len(targets))
+	for i, target := range targets {
+		series[i] = Series{
+			Name:    target.seriesName(),
+			Entries: make([]Entry, 0, len(sizes)),
+		}
 	}
 
-	entries := make([]Entry, 0, (statEnd-statStart)/statStep)
+sizesLoop:
+	for _, matrixSize = range sizes {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			fmt.Printf("budget of %v is spent, stopping before size %d\n", statBudget, matrixSize)
+			break
+		}
 
-	for matrixSize = int(statStart); matrixSize <= int(statEnd); matrixSize += int(statStep) {
-		m1 := makeSquareMatrix(matrixSize)
-		m2 := makeSquareMatrix(matrixSize)
+		m1 := matrix.NewMatrix[T](matrixSize, matrixSize)
+		m2 := matrix.NewMatrix[T](matrixSize, matrixSize)
 		fillMatrix(m1)
 		fillMatrix(m2)
 
-		time := timeFunction(
-			func() { addMatrices(m1, m2) },
-			int(statIterationsPerStep),
-		)
+		// operation name -> sequential result
+		references := make(map[string]*matrix.Matrix[T])
 
-		entries = append(entries, Entry{
-			Size: matrixSize,
-			Time: int(time),
-		})
+		for i, target := range targets {
+			targetConfig := target.config()
+			apply := func() { target.Apply(targetConfig, m1, m2) }
+			warmUp(apply, int(statWarmup))
 
-		fmt.Printf("statted at %d;elapsed %d%s\n", matrixSize, time, timeSuffix)
+			var memStatsBefore runtime.MemStats
+			if statMemory {
+				memStatsBefore = readMemStats()
+			}
+
+			var durations []time.Duration
+			if statAdaptive {
+				durations = adaptiveSamples(apply, int(statIterationsPerStep), statCITarget, statSizeBudget, deadline)
+			} else {
+				durations = timeSamples(apply, int(statIterationsPerStep))
+			}
+
+			entry := Entry{
+				Size:    matrixSize,
+				Samples: make([]float64, len(durations)),
+				Summary: summarize(durations),
+			}
+			if statMemory {
+				entry.Memory = memoryStatsSince(memStatsBefore)
+			}
+			var total time.Duration
+			for j, d := range durations {
+				entry.Samples[j] = toTimeUnit(d)
+				total += d
+			}
+			entry.Time = int(timePrecision(total) / int64(len(durations)))
+
+			if verify {
+				reference, ok := references[target.Name]
+				if !ok {
+					reference = matrix.SequentialReference(target.Operation, m1, m2)
+					references[target.Name] = reference
+				}
+				err := matrix.Compare(reference, target.Apply(targetConfig, m1, m2))
+				if err != nil {
+					return fmt.Errorf("verification of %s failed at %d: %w", series[i].Name, matrixSize, err)
+				}
+				entry.Verified = true
+			}
+			series[i].Entries = append(series[i].Entries, entry)
+
+			seriesName := ""
+			if len(series) > 1 {
+				seriesName = series[i].Name
+			}
+			err = writer.WriteEntry(seriesName, entry)
+			if err != nil {
+				return fmt.Errorf("error writing to %s: %w", outputPath, err)
+			}
+
+			if len(series) > 1 {
+				fmt.Printf("statted %s at %d;", series[i].Name, matrixSize)
+			} else {
+				fmt.Printf("statted at %d;", matrixSize)
+			}
+			fmt.Printf("elapsed %d%s;min %.2f;median %.2f;p95 %.2f;max %.2f;stddev %.2f;ci95 %.2f",
+				entry.Time, timeSuffix,
+				entry.Min, entry.Median, entry.P95, entry.Max, entry.StdDev, entry.CI95,
+			)
+			if statAdaptive {
+				fmt.Printf(";iterations %d", len(durations))
+			}
+			if entry.Memory != nil {
+				fmt.Printf(";allocs %d;allocated %dB;gc %d;gc pauses %.2f%s",
+					entry.Memory.Allocs, entry.Memory.AllocatedBytes,
+					entry.Memory.NumGC, entry.Memory.GCPauseTotal, timeSuffix,
+				)
+			}
+			fmt.Println()
+
+			// the rest of the targets of this size are left out
+			if !deadline.IsZero() && time.Now().After(deadline) && i < len(targets)-1 {
+				fmt.Printf("budget of %v is spent, stopping after %s at size %d\n", statBudget, series[i].Name, matrixSize)
+				break sizesLoop
+			}
+		}
 	}
 
-	command := strings.Join(os.Args, " ")
 	statResult := StatResult{
-		Command: command,
-		Entries: entries,
+		Command:     command,
+		Environment: environment,
+		TimeUnit:    timeSuffix,
+	}
+	if len(series) > 1 {
+		statResult.Series = series
+	} else {
+		statResult.Entries = series[0].Entries
 	}
 
-	bytes, err := json.Marshal(statResult)
-	if err != nil {
-		fmt.Println("error converting StatResult to json:")
-		fmt.Println(err)
-		os.Exit(1)
+	for i, target := range targets {
+		if target.threads == 0 || len(threadsSweep) == 0 {
+			continue
+		}
+		for j, baseline := range targets {
+			if baseline.threads == 0 && baseline.Name == target.Name {
+				speedup, efficiency := deriveSpeedup(series[j], series[i], target.threads)
+				statResult.Derived = append(statResult.Derived, speedup, efficiency)
+			}
+		}
 	}
 
-	_, err = f.Write(bytes)
+	err = writer.WriteResult(statResult)
 	if err != nil {
-		fmt.Println("error writing to", outputPath)
-		fmt.Println(err)
-		os.Exit(1)
+		return fmt.Errorf("error writing to %s: %w", outputPath, err)
 	}
+	return nil
 }
 
-func main() {
+func run[T matrix.Element]() error {
 	if statConfiguration {
-		stat()
-	} else {
-		runOnce()
+		return stat[T]()
+	}
+	return runOnce[T]()
+}
+
+func main() {
+	stopProfiling := startProfiling(cpuProfilePath, memProfilePath, tracePath)
+
+	var err error
+	switch elementType {
+	case "int":
+		err = run[int]()
+	case "int64":
+		err = run[int64]()
+	case "float64":
+		err = run[float64]()
+	}
+
+	// os.Exit doesn't run deferred functions, so profiling is stopped first
+	stopProfiling()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
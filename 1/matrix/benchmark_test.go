@@ -0,0 +1,62 @@
+package matrix
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+const (
+	benchmarkAddSize      = 512
+	benchmarkMultiplySize = 192
+)
+
+// benchmarkOperation runs op sequentially and with every strategy
+// on freshly spawned goroutines and on a pool
+func benchmarkOperation(b *testing.B, op Operation[float64], size int) {
+	r := rand.New(rand.NewSource(1))
+	m1 := randomMatrix(size, size, r)
+	m2 := randomMatrix(size, size, r)
+
+	threads := runtime.NumCPU()
+	pool := NewPool(threads)
+	defer pool.Close()
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			op.Apply(Config{}, m1, m2)
+		}
+	})
+	for _, name := range StrategyNames {
+		strategy, err := FindStrategy(name, 8)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(name+"/spawned", func(b *testing.B) {
+			c := Config{Threads: threads, Strategy: strategy}
+			for i := 0; i < b.N; i++ {
+				op.Apply(c, m1, m2)
+			}
+		})
+		b.Run(name+"/pool", func(b *testing.B) {
+			c := Config{Threads: threads, Strategy: strategy, Pool: pool}
+			for i := 0; i < b.N; i++ {
+				op.Apply(c, m1, m2)
+			}
+		})
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	benchmarkOperation(b, Operations(0.0)["add"], benchmarkAddSize)
+}
+
+func BenchmarkMultiply(b *testing.B) {
+	benchmarkOperation(b, Operations(0.0)["mul"], benchmarkMultiplySize)
+}
+
+// tiles are handed out over a channel regardless of the strategy,
+// so the strategies only differ in name here
+func BenchmarkMultiplyTiled(b *testing.B) {
+	benchmarkOperation(b, Operations(0.0)["mul-tiled"], benchmarkMultiplySize)
+}
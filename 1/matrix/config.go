@@ -0,0 +1,102 @@
+package matrix
+
+const DefaultTileSize = 64
+
+// Config describes how operations are run. Threads of 0 means running
+// sequentially, a nil Strategy means ChannelStrategy
+// and a TileSize of 0 means DefaultTileSize.
+// If Pool is set, its goroutines are used instead of starting Threads new ones
+type Config struct {
+	Threads  int
+	Strategy DistributionStrategy
+	TileSize int
+	Pool     *Pool
+}
+
+func (c Config) Concurrent() bool {
+	return c.Threads > 0
+}
+
+func (c Config) workers() Workers {
+	if c.Pool != nil {
+		return c.Pool
+	}
+	return spawnedWorkers(c.Threads)
+}
+
+func (c Config) tileSize() int {
+	if c.TileSize <= 0 {
+		return DefaultTileSize
+	}
+	return c.TileSize
+}
+
+// ForEachIndex calls f for every (i, j) in [0; rows) x [0; cols).
+// When running concurrently rows are distributed by the Strategy
+func (c Config) ForEachIndex(rows, cols int, f func(int, int)) {
+	if !c.Concurrent() {
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				f(i, j)
+			}
+		}
+		return
+	}
+
+	strategy := c.Strategy
+	if strategy == nil {
+		strategy = ChannelStrategy{}
+	}
+	strategy.Distribute(rows, c.workers(), func(i int) {
+		for j := 0; j < cols; j++ {
+			f(i, j)
+		}
+	})
+}
+
+type tile struct {
+	iLo, iHi int
+	jLo, jHi int
+}
+
+// ForEachTile calls f for every tile of TileSize x TileSize covering
+// [0; rows) x [0; cols). Per tile bounds are [lower; upper).
+// When running concurrently tiles are sent to workers over a shared channel
+func (c Config) ForEachTile(rows, cols int, f func(iLo, iHi, jLo, jHi int)) {
+	if !c.Concurrent() {
+		forEachTile(rows, cols, c.tileSize(), f)
+		return
+	}
+
+	channel := make(chan tile)
+
+	go func() {
+		forEachTile(rows, cols, c.tileSize(), func(iLo, iHi, jLo, jHi int) {
+			channel <- tile{iLo, iHi, jLo, jHi}
+		})
+		close(channel)
+	}()
+
+	c.workers().Run(func(int) {
+		for t := range channel {
+			f(t.iLo, t.iHi, t.jLo, t.jHi)
+		}
+	})
+}
+
+func forEachTile(iMax, jMax, tileSize int, f func(int, int, int, int)) {
+	for iLo := 0; iLo < iMax; iLo += tileSize {
+		iHi := minInt(iLo+tileSize, iMax)
+		for jLo := 0; jLo < jMax; jLo += tileSize {
+			jHi := minInt(jLo+tileSize, jMax)
+			f(iLo, iHi, jLo, jHi)
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
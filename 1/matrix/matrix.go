@@ -0,0 +1,70 @@
+// Package matrix contains matrices and operations on them that can be run
+// sequentially or concurrently with different work distribution strategies
+package matrix
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Element interface {
+	~int | ~int64 | ~float64
+}
+
+// Matrix stores its elements row by row in a single slice.
+// Element (i, j) is Data[i*Stride+j], so a view into a bigger matrix
+// shares its Data and keeps its Stride
+type Matrix[T Element] struct {
+	Rows   int
+	Cols   int
+	Stride int
+	Data   []T
+}
+
+func NewMatrix[T Element](rows, cols int) *Matrix[T] {
+	return &Matrix[T]{
+		Rows:   rows,
+		Cols:   cols,
+		Stride: cols,
+		Data:   make([]T, rows*cols),
+	}
+}
+
+func (m *Matrix[T]) At(i, j int) T {
+	return m.Data[i*m.Stride+j]
+}
+
+func (m *Matrix[T]) Set(i, j int, v T) {
+	m.Data[i*m.Stride+j] = v
+}
+
+func (m *Matrix[T]) Row(i int) []T {
+	return m.Data[i*m.Stride : i*m.Stride+m.Cols]
+}
+
+// View returns a rows x cols submatrix starting at (i, j) that shares m's data
+func (m *Matrix[T]) View(i, j, rows, cols int) *Matrix[T] {
+	view := &Matrix[T]{
+		Rows:   rows,
+		Cols:   cols,
+		Stride: m.Stride,
+	}
+	if rows > 0 {
+		start := i*m.Stride + j
+		view.Data = m.Data[start : start+(rows-1)*m.Stride+cols]
+	}
+	return view
+}
+
+func (m *Matrix[T]) String() string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < m.Rows; i++ {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		fmt.Fprint(&sb, m.Row(i))
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
@@ -0,0 +1,126 @@
+package matrix
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 4x5 matrix with element (i, j) = 10*i + j
+func indexMatrix() *Matrix[int] {
+	m := NewMatrix[int](4, 5)
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < m.Cols; j++ {
+			m.Set(i, j, 10*i+j)
+		}
+	}
+	return m
+}
+
+func TestRow(t *testing.T) {
+	m := indexMatrix()
+	for i := 0; i < m.Rows; i++ {
+		row := m.Row(i)
+		if len(row) != m.Cols {
+			t.Fatalf("row %d has %d elements, expected %d", i, len(row), m.Cols)
+		}
+		for j, v := range row {
+			if v != 10*i+j {
+				t.Errorf("row %d: element %d is %d, expected %d", i, j, v, 10*i+j)
+			}
+		}
+	}
+}
+
+func TestView(t *testing.T) {
+	m := indexMatrix()
+	view := m.View(1, 2, 2, 3)
+
+	if view.Rows != 2 || view.Cols != 3 || view.Stride != m.Stride {
+		t.Fatalf("view is %dx%d with stride %d, expected 2x3 with stride %d",
+			view.Rows, view.Cols, view.Stride, m.Stride)
+	}
+	for i := 0; i < view.Rows; i++ {
+		for j := 0; j < view.Cols; j++ {
+			if view.At(i, j) != m.At(i+1, j+2) {
+				t.Errorf("view (%d, %d) is %d, expected %d", i, j, view.At(i, j), m.At(i+1, j+2))
+			}
+		}
+	}
+	if !reflect.DeepEqual(view.Row(1), []int{22, 23, 24}) {
+		t.Errorf("view row 1 is %v, expected [22 23 24]", view.Row(1))
+	}
+
+	// views share data with the matrix
+	view.Set(1, 0, -1)
+	if m.At(2, 2) != -1 {
+		t.Errorf("setting an element of a view didn't change the matrix")
+	}
+	view.Row(0)[2] = -2
+	if m.At(1, 4) != -2 {
+		t.Errorf("changing a row of a view didn't change the matrix")
+	}
+	if m.At(1, 1) != 11 || m.At(2, 0) != 20 {
+		t.Errorf("changing a view changed elements outside of it")
+	}
+}
+
+func TestViewOfView(t *testing.T) {
+	m := indexMatrix()
+	view := m.View(1, 1, 3, 4).View(1, 1, 2, 2)
+	expected := [][]int{{22, 23}, {32, 33}}
+	for i, row := range expected {
+		if !reflect.DeepEqual(view.Row(i), row) {
+			t.Errorf("row %d is %v, expected %v", i, view.Row(i), row)
+		}
+	}
+}
+
+func TestEmptyView(t *testing.T) {
+	m := indexMatrix()
+	view := m.View(4, 0, 0, 5)
+	if view.Rows != 0 || len(view.Data) != 0 {
+		t.Errorf("expected an empty view, got %v", view)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	m := indexMatrix()
+	if err := Compare(m, indexMatrix()); err != nil {
+		t.Errorf("equal matrices: %v", err)
+	}
+
+	// views with a different stride are compared by their elements
+	view := m.View(1, 1, 2, 2)
+	dense := &Matrix[int]{Rows: 2, Cols: 2, Stride: 2, Data: []int{11, 12, 21, 22}}
+	if err := Compare(dense, view); err != nil {
+		t.Errorf("view: %v", err)
+	}
+
+	other := indexMatrix()
+	other.Set(2, 3, 0)
+	other.Set(3, 1, 0)
+	err := Compare(m, other)
+	expected := "first mismatch at (2, 3): expected 23, got 0"
+	if err == nil || err.Error() != expected {
+		t.Errorf("got error %v, expected %s", err, expected)
+	}
+
+	err = Compare(m, NewMatrix[int](5, 4))
+	expected = "expected a 4x5 matrix, got 5x4"
+	if err == nil || err.Error() != expected {
+		t.Errorf("got error %v, expected %s", err, expected)
+	}
+}
+
+func TestCompareFloatTolerance(t *testing.T) {
+	expected := &Matrix[float64]{Rows: 1, Cols: 2, Stride: 2, Data: []float64{1e6, 0.1 + 0.2}}
+	actual := &Matrix[float64]{Rows: 1, Cols: 2, Stride: 2, Data: []float64{1e6 + 1e-7, 0.3}}
+	if err := Compare(expected, actual); err != nil {
+		t.Errorf("differences in rounding: %v", err)
+	}
+
+	actual.Data[0] = 1e6 + 1
+	if err := Compare(expected, actual); err == nil {
+		t.Errorf("expected a mismatch")
+	}
+}
@@ -0,0 +1,158 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Operation[T Element] struct {
+	Name string
+	// Unary operations ignore their second argument
+	Unary bool
+	// Tiled operations dispatch work in tiles of Config.TileSize instead of rows
+	Tiled bool
+	// Products take an m1.Cols x m1.Rows second argument instead of m1.Rows x m1.Cols
+	Product bool
+	Apply   func(c Config, m1, m2 *Matrix[T]) *Matrix[T]
+	// Reference computes the same result in a simpler way to verify Apply against,
+	// Apply itself is used if it's nil
+	Reference func(c Config, m1, m2 *Matrix[T]) *Matrix[T]
+}
+
+// Operations returns all operations by their names,
+// scalar is used only by the "scalar" operation
+func Operations[T Element](scalar T) map[string]Operation[T] {
+	return map[string]Operation[T]{
+		"add": {Name: "add", Apply: Add[T]},
+		"sub": {Name: "sub", Apply: Subtract[T]},
+		"mul": {Name: "mul", Product: true, Apply: Multiply[T]},
+		"mul-tiled": {
+			Name:      "mul-tiled",
+			Tiled:     true,
+			Product:   true,
+			Apply:     MultiplyTiled[T],
+			Reference: Multiply[T],
+		},
+		"hadamard": {Name: "hadamard", Apply: HadamardProduct[T]},
+		"transpose": {
+			Name:  "transpose",
+			Unary: true,
+			Apply: func(c Config, m, _ *Matrix[T]) *Matrix[T] { return Transpose(c, m) },
+		},
+		"scalar": {
+			Name:  "scalar",
+			Unary: true,
+			Apply: func(c Config, m, _ *Matrix[T]) *Matrix[T] { return MultiplyByScalar(c, m, scalar) },
+		},
+	}
+}
+
+func OperationNames() []string {
+	ops := Operations[int](0)
+	names := make([]string, 0, len(ops))
+	for name := range ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func FindOperation[T Element](name string, scalar T) (Operation[T], error) {
+	op, ok := Operations(scalar)[name]
+	if !ok {
+		return Operation[T]{}, fmt.Errorf("unknown operation '%s', expected one of %s",
+			name, strings.Join(OperationNames(), "|"))
+	}
+	return op, nil
+}
+
+// SecondArgument creates an empty second argument for op given the first one
+func (op Operation[T]) SecondArgument(m1 *Matrix[T]) *Matrix[T] {
+	if op.Product {
+		return NewMatrix[T](m1.Cols, m1.Rows)
+	}
+	return NewMatrix[T](m1.Rows, m1.Cols)
+}
+
+func Add[T Element](c Config, m1, m2 *Matrix[T]) *Matrix[T] {
+	res := NewMatrix[T](m1.Rows, m1.Cols)
+	c.ForEachIndex(res.Rows, res.Cols,
+		func(i, j int) { res.Set(i, j, m1.At(i, j)+m2.At(i, j)) },
+	)
+	return res
+}
+
+func Subtract[T Element](c Config, m1, m2 *Matrix[T]) *Matrix[T] {
+	res := NewMatrix[T](m1.Rows, m1.Cols)
+	c.ForEachIndex(res.Rows, res.Cols,
+		func(i, j int) { res.Set(i, j, m1.At(i, j)-m2.At(i, j)) },
+	)
+	return res
+}
+
+func HadamardProduct[T Element](c Config, m1, m2 *Matrix[T]) *Matrix[T] {
+	res := NewMatrix[T](m1.Rows, m1.Cols)
+	c.ForEachIndex(res.Rows, res.Cols,
+		func(i, j int) { res.Set(i, j, m1.At(i, j)*m2.At(i, j)) },
+	)
+	return res
+}
+
+func Multiply[T Element](c Config, m1, m2 *Matrix[T]) *Matrix[T] {
+	res := NewMatrix[T](m1.Rows, m2.Cols)
+	c.ForEachIndex(res.Rows, res.Cols,
+		func(i, j int) {
+			var sum T
+			for k := 0; k < m1.Cols; k++ {
+				sum += m1.At(i, k) * m2.At(k, j)
+			}
+			res.Set(i, j, sum)
+		},
+	)
+	return res
+}
+
+// Every tile of the result is computed by a single worker. The k dimension
+// is walked in blocks of the same size, so that the touched parts of m1 and m2
+// stay in cache while they are being reused
+func MultiplyTiled[T Element](c Config, m1, m2 *Matrix[T]) *Matrix[T] {
+	res := NewMatrix[T](m1.Rows, m2.Cols)
+	tileSize := c.tileSize()
+	c.ForEachTile(res.Rows, res.Cols,
+		func(iLo, iHi, jLo, jHi int) {
+			resTile := res.View(iLo, jLo, iHi-iLo, jHi-jLo)
+			for kLo := 0; kLo < m1.Cols; kLo += tileSize {
+				kHi := minInt(kLo+tileSize, m1.Cols)
+				for i := 0; i < resTile.Rows; i++ {
+					row := resTile.Row(i)
+					m1Row := m1.Row(iLo + i)
+					for k := kLo; k < kHi; k++ {
+						a := m1Row[k]
+						m2Row := m2.Row(k)[jLo:jHi]
+						for j := range row {
+							row[j] += a * m2Row[j]
+						}
+					}
+				}
+			}
+		},
+	)
+	return res
+}
+
+func Transpose[T Element](c Config, m *Matrix[T]) *Matrix[T] {
+	res := NewMatrix[T](m.Cols, m.Rows)
+	c.ForEachIndex(res.Rows, res.Cols,
+		func(i, j int) { res.Set(i, j, m.At(j, i)) },
+	)
+	return res
+}
+
+func MultiplyByScalar[T Element](c Config, m *Matrix[T], k T) *Matrix[T] {
+	res := NewMatrix[T](m.Rows, m.Cols)
+	c.ForEachIndex(res.Rows, res.Cols,
+		func(i, j int) { res.Set(i, j, m.At(i, j)*k) },
+	)
+	return res
+}
@@ -0,0 +1,148 @@
+package matrix
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func randomMatrix(rows, cols int, r *rand.Rand) *Matrix[float64] {
+	m := NewMatrix[float64](rows, cols)
+	for i := range m.Data {
+		m.Data[i] = r.Float64()*200 - 100
+	}
+	return m
+}
+
+func randomIntMatrix(rows, cols int, r *rand.Rand) *Matrix[int] {
+	m := NewMatrix[int](rows, cols)
+	for i := range m.Data {
+		m.Data[i] = r.Intn(200) - 100
+	}
+	return m
+}
+
+// shapes are non-square and not multiples of testTileSize
+var shapes = []struct{ rows, cols int }{
+	{1, 1},
+	{1, 7},
+	{7, 1},
+	{5, 3},
+	{9, 14},
+	{17, 10},
+}
+
+const (
+	testThreads  = 3
+	testTileSize = 4
+)
+
+func testConfigs(t *testing.T) map[string]Config {
+	pool := NewPool(testThreads)
+	t.Cleanup(pool.Close)
+
+	configs := map[string]Config{
+		"sequential": {TileSize: testTileSize},
+	}
+	for _, name := range StrategyNames {
+		strategy, err := FindStrategy(name, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		configs[name+"/spawned"] = Config{Threads: testThreads, Strategy: strategy, TileSize: testTileSize}
+		configs[name+"/pool"] = Config{Threads: testThreads, Strategy: strategy, TileSize: testTileSize, Pool: pool}
+	}
+	return configs
+}
+
+func TestOperationsFloat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	configs := testConfigs(t)
+
+	for name, op := range Operations(2.5) {
+		for _, shape := range shapes {
+			m1 := randomMatrix(shape.rows, shape.cols, r)
+			m2 := op.SecondArgument(m1)
+			copy(m2.Data, randomMatrix(m2.Rows, m2.Cols, r).Data)
+			expected := SequentialReference(op, m1, m2)
+
+			for configName, c := range configs {
+				t.Run(fmt.Sprintf("%s/%dx%d/%s", name, shape.rows, shape.cols, configName), func(t *testing.T) {
+					err := Compare(expected, op.Apply(c, m1, m2))
+					if err != nil {
+						t.Error(err)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestOperationsInt(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	configs := testConfigs(t)
+
+	for name, op := range Operations(-3) {
+		for _, shape := range shapes {
+			m1 := randomIntMatrix(shape.rows, shape.cols, r)
+			m2 := op.SecondArgument(m1)
+			copy(m2.Data, randomIntMatrix(m2.Rows, m2.Cols, r).Data)
+			expected := SequentialReference(op, m1, m2)
+
+			for configName, c := range configs {
+				t.Run(fmt.Sprintf("%s/%dx%d/%s", name, shape.rows, shape.cols, configName), func(t *testing.T) {
+					err := Compare(expected, op.Apply(c, m1, m2))
+					if err != nil {
+						t.Error(err)
+					}
+				})
+			}
+		}
+	}
+}
+
+// the sequential results are checked against values computed by hand,
+// so that an error in both Apply and Reference doesn't go unnoticed
+func TestOperationsByHand(t *testing.T) {
+	m1 := &Matrix[int]{Rows: 2, Cols: 3, Stride: 3, Data: []int{1, 2, 3, 4, 5, 6}}
+	same := &Matrix[int]{Rows: 2, Cols: 3, Stride: 3, Data: []int{6, 5, 4, 3, 2, 1}}
+	product := &Matrix[int]{Rows: 3, Cols: 2, Stride: 2, Data: []int{1, 0, 0, 1, 2, 2}}
+
+	tests := []struct {
+		op       string
+		m2       *Matrix[int]
+		expected *Matrix[int]
+	}{
+		{"add", same, &Matrix[int]{Rows: 2, Cols: 3, Stride: 3, Data: []int{7, 7, 7, 7, 7, 7}}},
+		{"sub", same, &Matrix[int]{Rows: 2, Cols: 3, Stride: 3, Data: []int{-5, -3, -1, 1, 3, 5}}},
+		{"hadamard", same, &Matrix[int]{Rows: 2, Cols: 3, Stride: 3, Data: []int{6, 10, 12, 12, 10, 6}}},
+		{"mul", product, &Matrix[int]{Rows: 2, Cols: 2, Stride: 2, Data: []int{7, 8, 16, 17}}},
+		{"mul-tiled", product, &Matrix[int]{Rows: 2, Cols: 2, Stride: 2, Data: []int{7, 8, 16, 17}}},
+		{"transpose", nil, &Matrix[int]{Rows: 3, Cols: 2, Stride: 2, Data: []int{1, 4, 2, 5, 3, 6}}},
+		{"scalar", nil, &Matrix[int]{Rows: 2, Cols: 3, Stride: 3, Data: []int{2, 4, 6, 8, 10, 12}}},
+	}
+
+	for _, test := range tests {
+		op, err := FindOperation(test.op, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Compare(test.expected, op.Apply(Config{TileSize: 1}, m1, test.m2))
+		if err != nil {
+			t.Errorf("%s: %v", test.op, err)
+		}
+	}
+}
+
+func TestFindOperation(t *testing.T) {
+	for _, name := range OperationNames() {
+		_, err := FindOperation(name, 1)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	_, err := FindOperation("div", 1)
+	if err == nil {
+		t.Error("expected an error for an unknown operation")
+	}
+}
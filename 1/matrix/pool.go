@@ -0,0 +1,73 @@
+package matrix
+
+import "sync"
+
+// Workers run a worker function for every worker index in [0; Count())
+// concurrently and wait for all of them to finish
+type Workers interface {
+	Count() int
+	Run(worker func(int))
+}
+
+// spawnedWorkers start fresh goroutines on every Run
+type spawnedWorkers int
+
+func (s spawnedWorkers) Count() int { return int(s) }
+
+func (s spawnedWorkers) Run(worker func(int)) {
+	var wg sync.WaitGroup
+	wg.Add(int(s))
+	for w := 0; w < int(s); w++ {
+		go func(w int) {
+			defer wg.Done()
+			worker(w)
+		}(w)
+	}
+	wg.Wait()
+}
+
+// Pool keeps its goroutines between runs, so that they don't have
+// to be started for every operation. Every worker index is run by its own
+// goroutine and runs of the same pool don't overlap
+type Pool struct {
+	jobs     []chan func()
+	runMutex sync.Mutex
+}
+
+func NewPool(threads int) *Pool {
+	p := &Pool{jobs: make([]chan func(), threads)}
+	for w := range p.jobs {
+		p.jobs[w] = make(chan func())
+		go func(jobs chan func()) {
+			for job := range jobs {
+				job()
+			}
+		}(p.jobs[w])
+	}
+	return p
+}
+
+func (p *Pool) Count() int { return len(p.jobs) }
+
+func (p *Pool) Run(worker func(int)) {
+	p.runMutex.Lock()
+	defer p.runMutex.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(len(p.jobs))
+	for w, jobs := range p.jobs {
+		w := w
+		jobs <- func() {
+			defer wg.Done()
+			worker(w)
+		}
+	}
+	wg.Wait()
+}
+
+// Close stops the pool's goroutines, the pool can't be used afterwards
+func (p *Pool) Close() {
+	for _, jobs := range p.jobs {
+		close(jobs)
+	}
+}
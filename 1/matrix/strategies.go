@@ -0,0 +1,199 @@
+package matrix
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DistributionStrategy decides how rows in [0; rows) are handed out to workers
+type DistributionStrategy interface {
+	Name() string
+	Distribute(rows int, workers Workers, f func(int))
+}
+
+var StrategyNames = []string{"channel", "chunks", "cyclic", "chunked-channel", "stealing"}
+
+// FindStrategy creates a strategy by its name,
+// chunkSize is used only by the chunked-channel strategy
+func FindStrategy(name string, chunkSize int) (DistributionStrategy, error) {
+	switch name {
+	case "channel":
+		return ChannelStrategy{}, nil
+	case "chunks":
+		return StaticChunksStrategy{}, nil
+	case "cyclic":
+		return CyclicStrategy{}, nil
+	case "chunked-channel":
+		return ChunkedChannelStrategy{ChunkSize: chunkSize}, nil
+	case "stealing":
+		return WorkStealingStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown strategy '%s', expected one of %s", name, strings.Join(StrategyNames, "|"))
+}
+
+// ParseStrategies accepts a comma-delimited list of strategy names or "all"
+func ParseStrategies(names string, chunkSize int) ([]DistributionStrategy, error) {
+	split := strings.Split(names, ",")
+	if names == "all" {
+		split = StrategyNames
+	}
+
+	strategies := make([]DistributionStrategy, 0, len(split))
+	for _, name := range split {
+		s, err := FindStrategy(strings.TrimSpace(name), chunkSize)
+		if err != nil {
+			return nil, err
+		}
+		strategies = append(strategies, s)
+	}
+	return strategies, nil
+}
+
+// Every row is sent over a shared unbuffered channel
+type ChannelStrategy struct{}
+
+func (ChannelStrategy) Name() string { return "channel" }
+
+func (ChannelStrategy) Distribute(rows int, workers Workers, f func(int)) {
+	channel := make(chan int)
+
+	go func() {
+		for i := 0; i < rows; i++ {
+			channel <- i
+		}
+		close(channel)
+	}()
+
+	workers.Run(func(int) {
+		for i := range channel {
+			f(i)
+		}
+	})
+}
+
+// Every worker gets a contiguous block of rows up front
+type StaticChunksStrategy struct{}
+
+func (StaticChunksStrategy) Name() string { return "chunks" }
+
+func (StaticChunksStrategy) Distribute(rows int, workers Workers, f func(int)) {
+	threads := workers.Count()
+	chunk := (rows + threads - 1) / threads
+	workers.Run(func(w int) {
+		hi := minInt((w+1)*chunk, rows)
+		for i := w * chunk; i < hi; i++ {
+			f(i)
+		}
+	})
+}
+
+// Worker w gets rows w, w+threads, w+2*threads, ...
+type CyclicStrategy struct{}
+
+func (CyclicStrategy) Name() string { return "cyclic" }
+
+func (CyclicStrategy) Distribute(rows int, workers Workers, f func(int)) {
+	threads := workers.Count()
+	workers.Run(func(w int) {
+		for i := w; i < rows; i += threads {
+			f(i)
+		}
+	})
+}
+
+// Rows are sent over a shared channel ChunkSize rows at a time
+type ChunkedChannelStrategy struct {
+	ChunkSize int
+}
+
+func (s ChunkedChannelStrategy) Name() string {
+	return fmt.Sprintf("chunked-channel=%d", s.ChunkSize)
+}
+
+func (s ChunkedChannelStrategy) Distribute(rows int, workers Workers, f func(int)) {
+	channel := make(chan [2]int)
+
+	go func() {
+		for lo := 0; lo < rows; lo += s.ChunkSize {
+			channel <- [2]int{lo, minInt(lo+s.ChunkSize, rows)}
+		}
+		close(channel)
+	}()
+
+	workers.Run(func(int) {
+		for bounds := range channel {
+			for i := bounds[0]; i < bounds[1]; i++ {
+				f(i)
+			}
+		}
+	})
+}
+
+// Every worker starts with a contiguous block of rows and takes them from
+// the front. Workers that run out steal the back half of someone else's block
+type WorkStealingStrategy struct{}
+
+func (WorkStealingStrategy) Name() string { return "stealing" }
+
+type rowRange struct {
+	lo, hi int
+	mutex  sync.Mutex
+}
+
+func (r *rowRange) popFront() (int, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.lo >= r.hi {
+		return 0, false
+	}
+	r.lo++
+	return r.lo - 1, true
+}
+
+func (r *rowRange) stealBackHalf() (int, int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	mid := r.lo + (r.hi-r.lo)/2
+	lo, hi := mid, r.hi
+	r.hi = mid
+	return lo, hi
+}
+
+func (r *rowRange) reset(lo, hi int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lo, r.hi = lo, hi
+}
+
+func (WorkStealingStrategy) Distribute(rows int, workers Workers, f func(int)) {
+	threads := workers.Count()
+	ranges := make([]rowRange, threads)
+	chunk := (rows + threads - 1) / threads
+	for w := range ranges {
+		ranges[w].lo = minInt(w*chunk, rows)
+		ranges[w].hi = minInt((w+1)*chunk, rows)
+	}
+
+	workers.Run(func(w int) {
+		own := &ranges[w]
+		for {
+			if i, ok := own.popFront(); ok {
+				f(i)
+				continue
+			}
+
+			stolen := false
+			for v := 1; v < threads && !stolen; v++ {
+				lo, hi := ranges[(w+v)%threads].stealBackHalf()
+				if lo < hi {
+					own.reset(lo, hi)
+					stolen = true
+				}
+			}
+			if !stolen {
+				return
+			}
+		}
+	})
+}
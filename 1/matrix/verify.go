@@ -0,0 +1,49 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+)
+
+// Floating point results of concurrent and tiled operations may differ
+// from the sequential ones in the order of summation
+const floatTolerance = 1e-9
+
+func elementsEqual[T Element](a, b T) bool {
+	if a == b {
+		return true
+	}
+	if _, isFloat := any(a).(float64); !isFloat {
+		return false
+	}
+	fa, fb := float64(a), float64(b)
+	return math.Abs(fa-fb) <= floatTolerance*math.Max(math.Abs(fa), math.Abs(fb))
+}
+
+// Compare returns an error describing the first mismatching element
+func Compare[T Element](expected, actual *Matrix[T]) error {
+	if expected.Rows != actual.Rows || expected.Cols != actual.Cols {
+		return fmt.Errorf("expected a %dx%d matrix, got %dx%d",
+			expected.Rows, expected.Cols, actual.Rows, actual.Cols)
+	}
+
+	for i := 0; i < expected.Rows; i++ {
+		for j := 0; j < expected.Cols; j++ {
+			if !elementsEqual(expected.At(i, j), actual.At(i, j)) {
+				return fmt.Errorf("first mismatch at (%d, %d): expected %v, got %v",
+					i, j, expected.At(i, j), actual.At(i, j))
+			}
+		}
+	}
+	return nil
+}
+
+// SequentialReference computes the result of op sequentially
+// with its Reference function if there is one
+func SequentialReference[T Element](op Operation[T], m1, m2 *Matrix[T]) *Matrix[T] {
+	reference := op.Reference
+	if reference == nil {
+		reference = op.Apply
+	}
+	return reference(Config{}, m1, m2)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var outputFormats = []string{"json", "csv", "ndjson"}
+
+// StatWriter gets every entry as soon as it's measured and the whole
+// result at the end. Formats that can be streamed write entries right away,
+// so that a crash in the middle of a sweep doesn't lose what was measured
+type StatWriter interface {
+	WriteEntry(series string, e Entry) error
+	WriteResult(r StatResult) error
+}
+
+// Csv has no place for the command and the environment, so they are left out,
+// the time unit is repeated in a column of every row
+func newStatWriter(format string, w io.Writer, command string, environment Environment, unit string) (StatWriter, error) {
+	switch format {
+	case "json":
+		return &jsonWriter{w}, nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		err := cw.Write([]string{
+			"Series", "Size", "Time", "Min", "Max", "Mean", "Median", "P95", "StdDev", "CI95", "Samples",
+			"Allocs", "AllocatedBytes", "NumGC", "GCPauseTotal", "TimeUnit",
+		})
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+		return &csvWriter{cw, unit}, err
+	case "ndjson":
+		nw := &ndjsonWriter{json.NewEncoder(w)}
+		return nw, nw.encoder.Encode(struct {
+			Command     string
+			Environment Environment
+			TimeUnit    string
+		}{command, environment, unit})
+	}
+	return nil, fmt.Errorf("unknown output format '%s', expected one of %s", format, strings.Join(outputFormats, "|"))
+}
+
+type jsonWriter struct {
+	w io.Writer
+}
+
+func (*jsonWriter) WriteEntry(string, Entry) error { return nil }
+
+func (j *jsonWriter) WriteResult(r StatResult) error {
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(bytes)
+	return err
+}
+
+// The first line holds the command, the environment and the time unit, every next one is either
+// an entry of a series or a value of a derived series
+type ndjsonEntry struct {
+	Series string `json:",omitempty"`
+	Entry
+}
+
+type ndjsonDerivedEntry struct {
+	Derived string
+	DerivedEntry
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) WriteEntry(series string, e Entry) error {
+	return n.encoder.Encode(ndjsonEntry{series, e})
+}
+
+func (n *ndjsonWriter) WriteResult(r StatResult) error {
+	for _, d := range r.Derived {
+		for _, e := range d.Entries {
+			err := n.encoder.Encode(ndjsonDerivedEntry{d.Name, e})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type csvWriter struct {
+	w    *csv.Writer
+	unit string
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (c *csvWriter) WriteEntry(series string, e Entry) error {
+	samples := make([]string, len(e.Samples))
+	for i, s := range e.Samples {
+		samples[i] = formatFloat(s)
+	}
+
+	// memory columns are left empty when memory statistics weren't recorded
+	memory := make([]string, 4)
+	if e.Memory != nil {
+		memory = []string{
+			strconv.FormatUint(e.Memory.Allocs, 10),
+			strconv.FormatUint(e.Memory.AllocatedBytes, 10),
+			strconv.FormatUint(uint64(e.Memory.NumGC), 10),
+			formatFloat(e.Memory.GCPauseTotal),
+		}
+	}
+
+	c.w.Write(append([]string{
+		series,
+		strconv.Itoa(e.Size),
+		strconv.Itoa(e.Time),
+		formatFloat(e.Min),
+		formatFloat(e.Max),
+		formatFloat(e.Mean),
+		formatFloat(e.Median),
+		formatFloat(e.P95),
+		formatFloat(e.StdDev),
+		formatFloat(e.CI95),
+		strings.Join(samples, " "),
+	}, append(memory, c.unit)...))
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Derived series are left out, they can be recomputed from the rows
+func (*csvWriter) WriteResult(StatResult) error { return nil }
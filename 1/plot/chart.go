@@ -0,0 +1,380 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Chart is everything a backend needs to draw a plot
+type Chart struct {
+	Title  string
+	XLabel string
+	YLabel string
+	LogX   bool
+	LogY   bool
+	// the y axis starts at 0 even if every value is greater
+	YFromZero bool
+	Curves    []Curve
+	Width     int
+	Height    int
+}
+
+// renderer writes a chart to a file
+type renderer func(chart Chart, path string) error
+
+// backend name -> renderer, gnuplot is only registered when built with the glot tag
+var renderers = map[string]renderer{
+	"svg": renderSvg,
+	"png": renderPng,
+}
+
+func backendNames() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backendFor picks a backend by the output file extension unless one is set explicitly
+func backendFor(backend, path string) (renderer, error) {
+	if len(backend) == 0 {
+		backend = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	r, ok := renderers[backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend '%s', expected one of %s", backend, strings.Join(backendNames(), "|"))
+	}
+	return r, nil
+}
+
+type point struct {
+	X, Y float64
+}
+
+type stroke struct {
+	Color  color.RGBA
+	Width  float64
+	Dashed bool
+}
+
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is a surface a chart is drawn on, coordinates are in pixels
+// from the top left corner and text is positioned by its baseline.
+// Series and Hover are only used by interactive canvases: everything drawn
+// after Series belongs to the curve with that index (-1 is none of them)
+// and Hover marks a point that shows a tooltip
+type canvas interface {
+	Polyline(points []point, s stroke)
+	Polygon(points []point, fill color.RGBA)
+	Circle(center point, radius float64, fill color.RGBA)
+	Text(p point, text string, size float64, anchor textAnchor, vertical bool, c color.RGBA)
+	Series(index int)
+	Hover(p point, tip string)
+}
+
+var (
+	black     = color.RGBA{0, 0, 0, 255}
+	white     = color.RGBA{255, 255, 255, 255}
+	gridColor = color.RGBA{220, 220, 220, 255}
+
+	palette = []color.RGBA{
+		{148, 0, 211, 255},
+		{0, 158, 115, 255},
+		{86, 180, 233, 255},
+		{230, 159, 0, 255},
+		{240, 228, 66, 255},
+		{0, 114, 178, 255},
+		{229, 30, 16, 255},
+		{0, 0, 0, 255},
+	}
+)
+
+const (
+	fontSize      = 12
+	titleFontSize = 16
+	markerRadius  = 3
+)
+
+// axis maps values to pixels between from and to
+type axis struct {
+	min, max float64
+	from, to float64
+	log      bool
+}
+
+func (a axis) scale(v float64) float64 {
+	if a.log {
+		v, a.min, a.max = math.Log10(v), math.Log10(a.min), math.Log10(a.max)
+	}
+	return a.from + (v-a.min)/(a.max-a.min)*(a.to-a.from)
+}
+
+// ticks are multiples of 1, 2 or 5 times a power of ten on a linear axis
+// and powers of ten on a logarithmic one, which also gets 2 and 5 times
+// powers of ten when it shows less than a few decades
+func (a axis) ticks() []float64 {
+	ticks := make([]float64, 0)
+	if a.log {
+		for _, mantissas := range [][]float64{{1}, {1, 2, 5}} {
+			ticks = ticks[:0]
+			for e := math.Floor(math.Log10(a.min)); e <= math.Ceil(math.Log10(a.max)); e++ {
+				for _, m := range mantissas {
+					v := m * math.Pow(10, e)
+					if v >= a.min && v <= a.max {
+						ticks = append(ticks, v)
+					}
+				}
+			}
+			if len(ticks) >= 3 {
+				return ticks
+			}
+		}
+		// less than a decade is shown, so linear ticks are more useful
+		ticks = ticks[:0]
+	}
+
+	step := niceStep((a.max - a.min) / 6)
+	for v := math.Ceil(a.min/step) * step; v <= a.max+step*1e-9; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+func niceStep(raw float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// niceRange extends a linear range to the closest ticks around it
+// and pads a logarithmic one by a tenth of it
+func niceRange(min, max float64, log bool) (float64, float64) {
+	if log {
+		if min == max {
+			return min / 2, max * 2
+		}
+		pad := math.Pow(max/min, 0.1)
+		return min / pad, max * pad
+	}
+	if min == max {
+		return min - 1, max + 1
+	}
+	step := niceStep((max - min) / 6)
+	return math.Floor(min/step) * step, math.Ceil(max/step) * step
+}
+
+func formatTick(v float64) string {
+	if math.Abs(v) < 1e-12 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// chartRanges finds ranges of all the curves, non-positive values are skipped on log axes
+func chartRanges(chart Chart) (float64, float64, float64, float64) {
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for _, c := range chart.Curves {
+		for i := range c.X {
+			if (chart.LogX && c.X[i] <= 0) || (chart.LogY && c.Y[i] <= 0) {
+				continue
+			}
+			xMin = math.Min(xMin, c.X[i])
+			xMax = math.Max(xMax, c.X[i])
+			yMin = math.Min(yMin, c.Y[i])
+			yMax = math.Max(yMax, c.Y[i])
+			if c.Low != nil {
+				if !chart.LogY || c.Low[i] > 0 {
+					yMin = math.Min(yMin, c.Low[i])
+				}
+				yMax = math.Max(yMax, c.High[i])
+			}
+		}
+	}
+	if math.IsInf(xMin, 0) {
+		return 1, 10, 1, 10
+	}
+	if chart.YFromZero && !chart.LogY {
+		yMin = math.Min(yMin, 0)
+	}
+	return xMin, xMax, yMin, yMax
+}
+
+func drawChart(c canvas, chart Chart) {
+	width, height := float64(chart.Width), float64(chart.Height)
+	left, right, top, bottom := 80.0, width-20, 40.0, height-60
+
+	xMin, xMax, yMin, yMax := chartRanges(chart)
+	xMin, xMax = niceRange(xMin, xMax, chart.LogX)
+	yMin, yMax = niceRange(yMin, yMax, chart.LogY)
+	x := axis{xMin, xMax, left, right, chart.LogX}
+	y := axis{yMin, yMax, bottom, top, chart.LogY}
+
+	c.Polygon([]point{{0, 0}, {width, 0}, {width, height}, {0, height}}, white)
+
+	// grid and tick labels
+	for _, v := range x.ticks() {
+		px := x.scale(v)
+		c.Polyline([]point{{px, top}, {px, bottom}}, stroke{Color: gridColor, Width: 1})
+		c.Text(point{px, bottom + 16}, formatTick(v), fontSize, anchorMiddle, false, black)
+	}
+	for _, v := range y.ticks() {
+		py := y.scale(v)
+		c.Polyline([]point{{left, py}, {right, py}}, stroke{Color: gridColor, Width: 1})
+		c.Text(point{left - 6, py + 4}, formatTick(v), fontSize, anchorEnd, false, black)
+	}
+	c.Polyline([]point{{left, top}, {right, top}, {right, bottom}, {left, bottom}, {left, top}}, stroke{Color: black, Width: 1})
+
+	c.Text(point{width / 2, 26}, chart.Title, titleFontSize, anchorMiddle, false, black)
+	c.Text(point{(left + right) / 2, height - 20}, chart.XLabel, fontSize, anchorMiddle, false, black)
+	c.Text(point{20, (top + bottom) / 2}, chart.YLabel, fontSize, anchorMiddle, true, black)
+
+	colors := curveColors(chart.Curves)
+	for i, curve := range chart.Curves {
+		if curve.Low != nil {
+			c.Series(i)
+			drawErrors(c, curve, x, y, colors[i])
+		}
+	}
+	for i, curve := range chart.Curves {
+		c.Series(i)
+		s := stroke{Color: colors[i], Width: 2, Dashed: curve.Fit}
+		points := make([]point, 0, len(curve.X))
+		tips := make([]string, 0, len(curve.X))
+		for j := range curve.X {
+			if (chart.LogX && curve.X[j] <= 0) || (chart.LogY && curve.Y[j] <= 0) {
+				continue
+			}
+			points = append(points, point{x.scale(curve.X[j]), y.scale(curve.Y[j])})
+			tips = append(tips, pointTip(chart, curve, j))
+		}
+
+		if curve.Style != "points" {
+			c.Polyline(points, s)
+		}
+		if curve.Style != "lines" {
+			for _, p := range points {
+				c.Circle(p, markerRadius, s.Color)
+			}
+		}
+		for j, p := range points {
+			c.Hover(p, tips[j])
+		}
+	}
+
+	c.Series(-1)
+	drawLegend(c, chart.Curves, colors, left+10, top+10)
+}
+
+func pointTip(chart Chart, curve Curve, i int) string {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', 6, 64) }
+	tip := fmt.Sprintf("%s\n%s: %s\n%s: %s", curve.Name, chart.XLabel, format(curve.X[i]), chart.YLabel, format(curve.Y[i]))
+	if curve.Low != nil {
+		tip += fmt.Sprintf(" [%s, %s]", format(curve.Low[i]), format(curve.High[i]))
+	}
+	return tip
+}
+
+// drawErrors draws bands beneath all the curves, points with non-positive bounds
+// are left out on a log axis
+func drawErrors(c canvas, curve Curve, x, y axis, color color.RGBA) {
+	const capWidth = 4
+
+	upper := make([]point, 0, len(curve.X))
+	lower := make([]point, 0, len(curve.X))
+	for i := range curve.X {
+		if (x.log && curve.X[i] <= 0) || (y.log && curve.Low[i] <= 0) {
+			continue
+		}
+		px := x.scale(curve.X[i])
+		upper = append(upper, point{px, y.scale(curve.High[i])})
+		lower = append(lower, point{px, y.scale(curve.Low[i])})
+	}
+
+	if curve.Band {
+		band := append([]point{}, upper...)
+		for i := len(lower) - 1; i >= 0; i-- {
+			band = append(band, lower[i])
+		}
+		color.A = 64
+		c.Polygon(band, color)
+		return
+	}
+
+	s := stroke{Color: color, Width: 1}
+	for i := range upper {
+		u, l := upper[i], lower[i]
+		c.Polyline([]point{u, l}, s)
+		c.Polyline([]point{{u.X - capWidth, u.Y}, {u.X + capWidth, u.Y}}, s)
+		c.Polyline([]point{{l.X - capWidth, l.Y}, {l.X + capWidth, l.Y}}, s)
+	}
+}
+
+// curveColors takes colors from the palette in order, a fit has the color of the curve before it
+func curveColors(curves []Curve) []color.RGBA {
+	colors := make([]color.RGBA, len(curves))
+	next := 0
+	for i, curve := range curves {
+		if curve.Fit && i > 0 {
+			colors[i] = colors[i-1]
+			continue
+		}
+		colors[i] = palette[next%len(palette)]
+		next++
+	}
+	return colors
+}
+
+func drawLegend(c canvas, curves []Curve, colors []color.RGBA, x, y float64) {
+	if len(curves) == 0 {
+		return
+	}
+
+	// there are no font metrics here, so the width is an estimate
+	longest := 0
+	for _, curve := range curves {
+		longest = max(longest, len([]rune(curve.Name)))
+	}
+	width := 40 + float64(longest)*fontSize*0.6
+	height := float64(len(curves))*18 + 6
+
+	c.Polygon([]point{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}}, white)
+	c.Polyline([]point{{x, y}, {x + width, y}, {x + width, y + height}, {x, y + height}, {x, y}}, stroke{Color: black, Width: 1})
+
+	for i, curve := range curves {
+		ly := y + 15 + float64(i)*18
+		s := stroke{Color: colors[i], Width: 2, Dashed: curve.Fit}
+		if curve.Style != "points" {
+			c.Polyline([]point{{x + 6, ly - 4}, {x + 30, ly - 4}}, s)
+		}
+		if curve.Style != "lines" {
+			c.Circle(point{x + 18, ly - 4}, markerRadius, s.Color)
+		}
+		c.Text(point{x + 36, ly}, curve.Name, fontSize, anchorStart, false, black)
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// minmax draws whiskers from the smallest to the largest value
+// and stddev draws a band one standard deviation around the mean
+var errorStyles = []string{"none", "minmax", "stddev"}
+
+// groupByLegend puts entries with the same legend together,
+// so that repeated runs are plotted as a single curve
+func groupByLegend(entries []PlotEntry) [][]PlotEntry {
+	groups := make([][]PlotEntry, 0, len(entries))
+	index := make(map[string]int)
+	for _, entry := range entries {
+		i, ok := index[entry.legendName]
+		if !ok {
+			i = len(groups)
+			index[entry.legendName] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], entry)
+	}
+	return groups
+}
+
+// groupValues collects every sample of every run for each size, a measurement
+// without samples contributes its mean. Center is the mean of a single run
+// or the average of means of repeated runs
+func groupValues(group []PlotEntry) ([]int, map[int]float64, map[int][]float64) {
+	sizes := make([]int, 0)
+	times := make(map[int][]float64)
+	values := make(map[int][]float64)
+	for _, entry := range group {
+		for _, m := range entry.Measurements {
+			if _, ok := times[m.Size]; !ok {
+				sizes = append(sizes, m.Size)
+			}
+			times[m.Size] = append(times[m.Size], m.Mean)
+			if len(m.Samples) > 0 {
+				values[m.Size] = append(values[m.Size], m.Samples...)
+			} else {
+				values[m.Size] = append(values[m.Size], m.Mean)
+			}
+		}
+	}
+
+	sort.Ints(sizes)
+	center := make(map[int]float64, len(sizes))
+	for _, size := range sizes {
+		center[size] = mean(times[size])
+	}
+	return sizes, center, values
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// errorBounds returns the lower and the upper end of an error bar
+func errorBounds(style string, center float64, values []float64) (float64, float64) {
+	if style == "stddev" {
+		sd := stdDev(values)
+		return center - sd, center + sd
+	}
+
+	low, high := values[0], values[0]
+	for _, v := range values[1:] {
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+	return low, high
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// powerFit fits y = a*x^k by least squares on log y = log a + k*log x.
+// Points with non-positive coordinates are skipped, ok is false
+// when less than two points are left or all of them have the same x
+func powerFit(x, y []float64) (a, k, r2 float64, ok bool) {
+	logX := make([]float64, 0, len(x))
+	logY := make([]float64, 0, len(y))
+	for i := range x {
+		if x[i] > 0 && y[i] > 0 {
+			logX = append(logX, math.Log(x[i]))
+			logY = append(logY, math.Log(y[i]))
+		}
+	}
+	if len(logX) < 2 {
+		return 0, 0, 0, false
+	}
+
+	meanX, meanY := mean(logX), mean(logY)
+	sxx, sxy, syy := 0.0, 0.0, 0.0
+	for i := range logX {
+		sxx += (logX[i] - meanX) * (logX[i] - meanX)
+		sxy += (logX[i] - meanX) * (logY[i] - meanY)
+		syy += (logY[i] - meanY) * (logY[i] - meanY)
+	}
+	if sxx == 0 {
+		return 0, 0, 0, false
+	}
+
+	k = sxy / sxx
+	a = math.Exp(meanY - k*meanX)
+	r2 = 1.0
+	if syy > 0 {
+		r2 = sxy * sxy / (sxx * syy)
+	}
+	return a, k, r2, true
+}
+
+// fitCurves adds a fitted a*n^k curve after every curve. Fitted curves are sampled
+// at evenly spaced points on a log scale, so they look smooth on both kinds of axes
+func fitCurves(curves []Curve) []Curve {
+	const fitPoints = 50
+
+	res := make([]Curve, 0, 2*len(curves))
+	for _, curve := range curves {
+		res = append(res, curve)
+
+		a, k, r2, ok := powerFit(curve.X, curve.Y)
+		if !ok {
+			fmt.Printf("%s: not enough points to fit\n", curve.Name)
+			continue
+		}
+		fmt.Printf("%s: time = %.4g * n^%.3f, R^2 = %.4f\n", curve.Name, a, k, r2)
+
+		xMin, xMax := math.Inf(1), math.Inf(-1)
+		for _, x := range curve.X {
+			if x > 0 {
+				xMin, xMax = math.Min(xMin, x), math.Max(xMax, x)
+			}
+		}
+		fit := Curve{
+			Name:  fmt.Sprintf("%s (n^%.2f)", curve.Name, k),
+			Style: "lines",
+			X:     make([]float64, fitPoints),
+			Y:     make([]float64, fitPoints),
+			Fit:   true,
+		}
+		for i := range fit.X {
+			fit.X[i] = xMin * math.Pow(xMax/xMin, float64(i)/(fitPoints-1))
+			fit.Y[i] = a * math.Pow(fit.X[i], k)
+		}
+		res = append(res, fit)
+	}
+	return res
+}
@@ -0,0 +1,58 @@
+//go:build glot
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/Arafatk/glot"
+)
+
+// glot panics on start when gnuplot isn't installed,
+// so it is built only with the glot tag
+func init() {
+	renderers["gnuplot"] = renderGnuplot
+}
+
+func renderGnuplot(chart Chart, path string) error {
+	dimensions := 2
+	persist := false
+	debug := false
+	plot, err := glot.NewPlot(dimensions, persist, debug)
+	if err != nil {
+		return fmt.Errorf("error creating a plot: %w", err)
+	}
+
+	// Find and set graph ranges
+	xMin, xMax, yMin, yMax := chartRanges(chart)
+	plot.Cmd("set xrange [%g:%g]", xMin, xMax)
+	plot.Cmd("set yrange [%g:%g]", yMin, yMax)
+	if chart.LogX {
+		plot.SetLogscale("x", 10)
+	}
+	if chart.LogY {
+		plot.SetLogscale("y", 10)
+	}
+
+	if len(chart.Title) > 0 {
+		plot.SetTitle(chart.Title)
+	}
+	plot.SetXLabel(chart.XLabel)
+	plot.SetYLabel(chart.YLabel)
+
+	for _, curve := range chart.Curves {
+		if curve.Low != nil {
+			fmt.Printf("errors of '%s' aren't drawn by the gnuplot backend\n", curve.Name)
+		}
+		err = plot.AddPointGroup(
+			curve.Name,
+			curve.Style,
+			[][]float64{curve.X, curve.Y},
+		)
+		if err != nil {
+			return fmt.Errorf("error adding a point group to %s: %w", curve.Name, err)
+		}
+	}
+
+	return plot.SavePlot(path)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"parallel-computations/results"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// htmlCanvas is an svg canvas that groups elements of every curve,
+// so that they can be hidden, and marks points that show tooltips
+type htmlCanvas struct {
+	*svgCanvas
+	inGroup bool
+}
+
+func (h *htmlCanvas) Series(index int) {
+	if h.inGroup {
+		h.b.WriteString("</g>\n")
+	}
+	h.inGroup = index >= 0
+	if h.inGroup {
+		fmt.Fprintf(&h.b, `<g class="series" data-series="%d">`+"\n", index)
+	}
+}
+
+func (h *htmlCanvas) Hover(p point, tip string) {
+	fmt.Fprintf(&h.b, `<circle class="hover" cx="%.1f" cy="%.1f" r="6" data-tip="%s"/>`+"\n", p.X, p.Y, html.EscapeString(tip))
+}
+
+type reportFile struct {
+	Filename     string
+	Legend       string
+	Command      string
+	TimeUnit     string
+	Environment  [][2]string
+	Measurements []results.Measurement
+}
+
+type report struct {
+	Title  string
+	Text   map[string]string
+	Svg    template.HTML
+	Series []string
+	Files  []reportFile
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"samples": func(samples []float64) string {
+		s := make([]string, len(samples))
+		for i, v := range samples {
+			s[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return strings.Join(s, " ")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+code { background: #f4f4f4; padding: 2px 4px; }
+circle.hover { fill: transparent; cursor: pointer; }
+#tooltip { position: absolute; display: none; white-space: pre; background: #fff; border: 1px solid #333; padding: 4px 8px; font-size: 12px; pointer-events: none; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{.Svg}}
+<div>
+{{range $i, $name := .Series}}<label><input type="checkbox" data-series="{{$i}}" checked> {{$name}}</label><br>
+{{end}}</div>
+{{range .Files}}
+<h2>{{.Legend}}</h2>
+<p><code>{{.Filename}}</code></p>
+<p>{{index $.Text "command"}}: <code>{{.Command}}</code></p>
+{{if .Environment}}<table>
+<tr><th colspan="2">{{index $.Text "environment"}}</th></tr>
+{{range .Environment}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>{{end}}
+<table>
+<tr><th>{{index $.Text "size"}}</th><th>{{index $.Text "time"}}{{if .TimeUnit}} ({{.TimeUnit}}){{end}}</th><th>{{index $.Text "samples"}}</th></tr>
+{{range .Measurements}}<tr><td>{{.Size}}</td><td>{{.Mean}}</td><td>{{samples .Samples}}</td></tr>
+{{end}}</table>
+{{end}}
+<div id="tooltip"></div>
+<script>
+document.querySelectorAll('input[data-series]').forEach(function (box) {
+	box.addEventListener('change', function () {
+		document.querySelectorAll('g.series[data-series="' + box.dataset.series + '"]').forEach(function (g) {
+			g.style.display = box.checked ? '' : 'none';
+		});
+	});
+});
+
+var tooltip = document.getElementById('tooltip');
+document.querySelectorAll('circle.hover').forEach(function (point) {
+	point.addEventListener('mouseenter', function () {
+		tooltip.textContent = point.dataset.tip;
+		tooltip.style.display = 'block';
+	});
+	point.addEventListener('mousemove', function (e) {
+		tooltip.style.left = e.pageX + 12 + 'px';
+		tooltip.style.top = e.pageY + 12 + 'px';
+	});
+	point.addEventListener('mouseleave', function () {
+		tooltip.style.display = 'none';
+	});
+});
+</script>
+</body>
+</html>
+`))
+
+func environmentRows(environment map[string]interface{}) [][2]string {
+	keys := make([]string, 0, len(environment))
+	for key := range environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rows := make([][2]string, len(keys))
+	for i, key := range keys {
+		rows[i] = [2]string{key, fmt.Sprint(environment[key])}
+	}
+	return rows
+}
+
+// writeHtmlReport writes a page with the chart, which works without a network
+// connection, followed by the metadata and the measurements of every file
+func writeHtmlReport(path string, chart Chart, entries []PlotEntry) error {
+	c := &htmlCanvas{svgCanvas: &svgCanvas{}}
+	drawChart(c, chart)
+	c.Series(-1)
+
+	r := report{
+		Title: chart.Title,
+		Text:  text.report,
+		Svg:   template.HTML(c.String(chart.Width, chart.Height)),
+	}
+	if len(r.Title) == 0 {
+		r.Title = text.report["title"]
+	}
+	for _, curve := range chart.Curves {
+		r.Series = append(r.Series, curve.Name)
+	}
+	for _, entry := range entries {
+		r.Files = append(r.Files, reportFile{
+			Filename:     entry.filename,
+			Legend:       entry.legendName,
+			Command:      entry.Command,
+			TimeUnit:     entry.TimeUnit,
+			Environment:  environmentRows(entry.Environment),
+			Measurements: entry.Measurements,
+		})
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return reportTemplate.Execute(f, r)
+}
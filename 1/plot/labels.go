@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// labels are the strings a plot is made of in one language
+type labels struct {
+	size        string
+	sizeSquared string
+	time        string
+	speedup     string
+	efficiency  string
+	amdahl      string
+	// stat file time unit -> its name
+	units map[string]string
+	// strings of an html report
+	report map[string]string
+}
+
+var localeLabels = map[string]labels{
+	"uk": {
+		size:        "Розмір матриці",
+		sizeSquared: "(Розмір матриці)^2",
+		time:        "Час",
+		speedup:     "Прискорення",
+		efficiency:  "Ефективність",
+		amdahl:      "закон Амдала",
+		units: map[string]string{
+			"ns": "наносекунди",
+			"ms": "мілісекунди",
+		},
+		report: map[string]string{
+			"title":       "Звіт",
+			"command":     "Команда",
+			"environment": "Середовище",
+			"size":        "Розмір",
+			"time":        "Час",
+			"samples":     "Вибірки",
+		},
+	},
+	"en": {
+		size:        "Matrix size",
+		sizeSquared: "(Matrix size)^2",
+		time:        "Time",
+		speedup:     "Speedup",
+		efficiency:  "Efficiency",
+		amdahl:      "Amdahl's law",
+		units: map[string]string{
+			"ns": "nanoseconds",
+			"ms": "milliseconds",
+		},
+		report: map[string]string{
+			"title":       "Report",
+			"command":     "Command",
+			"environment": "Environment",
+			"size":        "Size",
+			"time":        "Time",
+			"samples":     "Samples",
+		},
+	},
+}
+
+func localeNames() []string {
+	names := make([]string, 0, len(localeLabels))
+	for name := range localeLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// timeLabel names the unit of the stat files, which is left out when
+// older stat files don't record it or the files disagree
+func (l labels) timeLabel(entries []PlotEntry) string {
+	unit := ""
+	for i, entry := range entries {
+		if i > 0 && entry.TimeUnit != unit {
+			fmt.Printf("'%s' and '%s' have different time units, the unit is left out of the label\n",
+				entries[0].filename, entry.filename)
+			return l.time
+		}
+		unit = entry.TimeUnit
+	}
+
+	name, ok := l.units[unit]
+	if !ok {
+		return l.time
+	}
+	return fmt.Sprintf("%s (%s)", l.time, name)
+}
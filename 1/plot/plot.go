@@ -1,183 +1,340 @@
 package main
 
 import (
-	"encoding/json"
-	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"parallel-computations/results"
+	"path/filepath"
 	"strings"
-
-	"github.com/Arafatk/glot"
 )
 
 var (
 	scaleForCells bool
+	plotMode      string
+	baselinePath  string
+	backend       string
+	logX          bool
+	logY          bool
+	width         int
+	height        int
+	errorStyle    string
+	title         string
+	xLabel        string
+	yLabel        string
+	text          labels
+	htmlPath      string
+	fitPower      bool
+	// set when -o is passed explicitly
+	imageRequested bool
 )
 
-type Measurement struct {
-	Size int
-	Time int
-}
-
-type StatResult struct {
-	Command      string
-	Measurements []Measurement `json:"Entries"`
-}
-
+// PlotEntry is a single series of a stat file
 type PlotEntry struct {
 	filename   string
 	legendName string
-	*StatResult
+	seriesName string
+	*results.StatResult
+	Measurements []results.Measurement
 }
 
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return !errors.Is(err, os.ErrNotExist)
+// Curve is a single line on a plot, Style is one of lines, points and linepoints.
+// Fit curves are drawn dashed by built-in backends.
+// Low and High are error bounds of every point, they are drawn as whiskers
+// or as a translucent band if Band is set
+type Curve struct {
+	Name  string
+	Style string
+	X     []float64
+	Y     []float64
+	Fit   bool
+	Low   []float64
+	High  []float64
+	Band  bool
 }
 
 func parseArgsAndStatFiles() ([]PlotEntry, string) {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: plot [flags] file[:legend]...")
+		fmt.Fprintln(flag.CommandLine.Output(), "file can be a glob pattern, files matched by it share the legend. "+
+			"Legend defaults to the series name or the command the file was produced by")
+		flag.PrintDefaults()
+	}
 	outPath := flag.String("o", "plot.png", "path to outputted plot")
 	flag.BoolVar(&scaleForCells, "xscale-cells", false, "scale by cells, not columns")
+	flag.StringVar(&plotMode, "mode", "time", "what to plot ("+strings.Join(plotModes, "|")+"), "+
+		"speedup and efficiency are computed against -baseline")
+	flag.StringVar(&baselinePath, "baseline", "", "stat file of a sequential run (works only in speedup and efficiency modes)")
+	flag.StringVar(&backend, "backend", "", "how to render the plot ("+strings.Join(backendNames(), "|")+"), "+
+		"picked by the output file extension if not specified")
+	flag.BoolVar(&fitPower, "fit", false, "fit every curve to a*n^k, print the exponent and plot the fitted curve "+
+		"(works only in time mode, n is the number of cells with -xscale-cells)")
+	flag.BoolVar(&logX, "logx", false, "use a logarithmic x axis")
+	flag.BoolVar(&logY, "logy", false, "use a logarithmic y axis")
+	flag.IntVar(&width, "width", 800, "plot width in pixels")
+	flag.IntVar(&height, "height", 600, "plot height in pixels")
+	flag.StringVar(&errorStyle, "errors", "none", "how to show the spread of measurements ("+strings.Join(errorStyles, "|")+"), "+
+		"computed from samples and from repeated runs, which are files with the same legend (works only in time mode)")
+	flag.StringVar(&title, "title", "", "plot title")
+	flag.StringVar(&xLabel, "xlabel", "", "x axis label (is derived from the mode if not specified)")
+	flag.StringVar(&yLabel, "ylabel", "", "y axis label (is derived from the mode and the time unit of stat files if not specified)")
+	flag.StringVar(&htmlPath, "html", "", "write an interactive html report with the plot and the measurements to this file "+
+		"(the plot image is then written only if -o is passed)")
+	locale := flag.String("locale", "uk", "language of derived labels ("+strings.Join(localeNames(), "|")+")")
 	flag.Parse()
 
-	fmt.Println("outPath =", *outPath)
+	flag.Visit(func(f *flag.Flag) {
+		imageRequested = imageRequested || f.Name == "o"
+	})
 
-	entriesToPlot := make([]PlotEntry, 0, len(os.Args))
-	for _, v := range flag.Args()[1:] {
-		split := strings.Split(v, ",")
-		if len(split) != 2 {
-			fmt.Printf("number of comma-delimited values in '%s' is not equal to 2, skipping\n", v)
-			continue
-		}
+	var ok bool
+	text, ok = localeLabels[*locale]
+	if !ok {
+		fmt.Printf("unknown locale '%s', expected one of %s\n", *locale, strings.Join(localeNames(), "|"))
+		os.Exit(1)
+	}
 
-		entry := PlotEntry{
-			filename:   split[0],
-			legendName: split[1],
-		}
-		if !fileExists(entry.filename) {
-			fmt.Printf("file '%s' doesn't exist, skipping\n", entry.filename)
-		}
-		entriesToPlot = append(entriesToPlot, entry)
+	validMode := false
+	for _, mode := range plotModes {
+		validMode = validMode || mode == plotMode
+	}
+	if !validMode {
+		fmt.Printf("unknown mode '%s', expected one of %s\n", plotMode, strings.Join(plotModes, "|"))
+		os.Exit(1)
+	}
+	if !containsString(errorStyles, errorStyle) {
+		fmt.Printf("unknown error style '%s', expected one of %s\n", errorStyle, strings.Join(errorStyles, "|"))
+		os.Exit(1)
+	}
+	if plotMode != "time" && errorStyle != "none" {
+		fmt.Println("errors can only be shown in time mode")
+		os.Exit(1)
+	}
+	if plotMode != "time" && fitPower {
+		fmt.Println("curves can only be fitted in time mode")
+		os.Exit(1)
+	}
+	if plotMode != "time" && len(baselinePath) == 0 {
+		fmt.Println(plotMode, "mode requires a -baseline stat file")
+		os.Exit(1)
 	}
 
-	for i := 0; i < len(entriesToPlot); i++ {
-		f, err := os.Open(entriesToPlot[i].filename)
-		if err != nil {
-			fmt.Println("error opening", entriesToPlot[i].filename)
-			fmt.Println(err)
-			os.Exit(1)
-		}
+	if flag.NArg() == 0 {
+		fmt.Println("no stat files to plot")
+		flag.Usage()
+		os.Exit(1)
+	}
 
-		err = json.NewDecoder(f).Decode(&entriesToPlot[i].StatResult)
+	if len(htmlPath) == 0 || imageRequested {
+		fmt.Println("outPath =", *outPath)
+	}
+
+	entriesToPlot := make([]PlotEntry, 0, flag.NArg())
+	for _, arg := range flag.Args() {
+		entries, err := expandArg(arg)
 		if err != nil {
-			fmt.Println("error decoding", entriesToPlot[i].filename)
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		entriesToPlot = append(entriesToPlot, entries...)
 	}
 
 	return entriesToPlot, *outPath
 }
 
-func findMeasurementsMinOrMax(entries *[]Measurement, f func(old, cur Measurement) bool) Measurement {
-	res := (*entries)[0]
-	for _, v := range (*entries)[1:] {
-		if f(v, res) {
-			res = v
-		}
+// expandArg loads every file matched by a file[:legend] argument,
+// each series of a file becomes a separate entry
+func expandArg(arg string) ([]PlotEntry, error) {
+	pattern, legend, _ := strings.Cut(arg, ":")
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("'%s' doesn't match any file", pattern)
 	}
-	return res
-}
 
-func findMeasurementRanges(m *[]Measurement) (int, int, int, int) {
-	xMin := findMeasurementsMinOrMax(m,
-		func(old, cur Measurement) bool { return old.Size < cur.Size }).Size
-	xMax := findMeasurementsMinOrMax(m,
-		func(old, cur Measurement) bool { return old.Size > cur.Size }).Size
-	yMin := findMeasurementsMinOrMax(m,
-		func(old, cur Measurement) bool { return old.Time < cur.Time }).Time
-	yMax := findMeasurementsMinOrMax(m,
-		func(old, cur Measurement) bool { return old.Time > cur.Time }).Time
-
-	return xMin, xMax, yMin, yMax
+	entries := make([]PlotEntry, 0, len(paths))
+	for _, path := range paths {
+		res, err := results.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, series := range res.Series {
+			entries = append(entries, PlotEntry{
+				filename:     path,
+				legendName:   entryLegend(legend, path, res, series),
+				seriesName:   series.Name,
+				StatResult:   res,
+				Measurements: series.Measurements,
+			})
+		}
+	}
+	return entries, nil
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// entryLegend adds a series name to the legend passed for a file with several series.
+// Without a legend the series name is used, then the command and then the file name
+func entryLegend(legend, path string, res *results.StatResult, series results.Series) string {
+	switch {
+	case len(legend) > 0 && len(res.Series) > 1:
+		return legend + " " + series.Name
+	case len(legend) > 0:
+		return legend
+	case len(series.Name) > 0:
+		return series.Name
+	case len(res.Command) > 0:
+		return commandLegend(res.Command)
 	}
-	return b
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
+// commandLegend names a run after its operation and thread count the way lab 1 names series
+func commandLegend(command string) string {
+	op := flagValue(command, "op")
+	if len(op) == 0 {
+		op = "add"
+	}
+	if threads := threadsFromCommand(command); threads > 0 {
+		return fmt.Sprintf("%s threads=%d", op, threads)
 	}
-	return b
+	return op + " sequential"
 }
 
-func main() {
-	entriesToPlot, outPath := parseArgsAndStatFiles()
-
-	dimensions := 2
-	persist := false
-	debug := false
-	plot, err := glot.NewPlot(dimensions, persist, debug)
+func loadStatResult(path string) *results.StatResult {
+	res, err := results.Load(path)
 	if err != nil {
-		fmt.Println("error creating a plot")
-		fmt.Println("error")
+		fmt.Println(err)
 		os.Exit(1)
 	}
+	return res
+}
 
-	// Find and set graph ranges
-	xMin, xMax, yMin, yMax := findMeasurementRanges(&entriesToPlot[0].Measurements)
-	for _, plotEntry := range entriesToPlot[1:] {
-		m := plotEntry.Measurements
-		_xMin, _xMax, _yMin, _yMax := findMeasurementRanges(&m)
-		xMin = min(xMin, _xMin)
-		xMax = max(xMax, _xMax)
-		yMin = min(yMin, _yMin)
-		yMax = max(yMax, _yMax)
-	}
-	plot.SetXrange(xMin, xMax)
-	plot.SetYrange(yMin, yMax)
-
-	if scaleForCells {
-		plot.SetXLabel("(Розмір матриці)^2")
-	} else {
-		plot.SetXLabel("Розмір матриці")
-	}
-	plot.SetYLabel("Час (наносекунди)")
+func timeCurves(entries []PlotEntry) []Curve {
+	groups := groupByLegend(entries)
+	curves := make([]Curve, 0, len(groups))
+	for _, group := range groups {
+		sizes, center, values := groupValues(group)
+		curve := Curve{
+			Name:  group[0].legendName,
+			Style: "lines",
+			X:     make([]float64, len(sizes)),
+			Y:     make([]float64, len(sizes)),
+		}
+		for i, size := range sizes {
+			curve.X[i] = float64(size)
+			curve.Y[i] = center[size]
+		}
 
-	for _, plotEntry := range entriesToPlot {
-		m := plotEntry.Measurements
-		data := make([][]int, 2)
-		data[0] = make([]int, len(m))
-		data[1] = make([]int, len(m))
-		for i := 0; i < len(m); i++ {
-			data[0][i] = m[i].Size
-			data[1][i] = m[i].Time
+		if errorStyle != "none" {
+			curve.Low = make([]float64, len(sizes))
+			curve.High = make([]float64, len(sizes))
+			curve.Band = errorStyle == "stddev"
+			for i, size := range sizes {
+				curve.Low[i], curve.High[i] = errorBounds(errorStyle, center[size], values[size])
+			}
 		}
 
 		if scaleForCells {
-			for i := 0; i < len(data); i++ {
-				data[0][i] *= data[0][i]
+			for i := 0; i < len(curve.X); i++ {
+				curve.X[i] *= curve.X[i]
 			}
 		}
+		curves = append(curves, curve)
+	}
+	return curves
+}
 
-		err = plot.AddPointGroup(
-			plotEntry.legendName,
-			"lines",
-			data,
-		)
+func main() {
+	entriesToPlot, outPath := parseArgsAndStatFiles()
+
+	if len(xLabel) == 0 {
+		xLabel = text.size
+		if scaleForCells {
+			xLabel = text.sizeSquared
+		}
+	}
 
+	reportEntries := entriesToPlot
+	var curves []Curve
+	derivedYLabel := ""
+	switch plotMode {
+	case "time":
+		curves = timeCurves(entriesToPlot)
+		if fitPower {
+			curves = fitCurves(curves)
+		}
+		derivedYLabel = text.timeLabel(entriesToPlot)
+	case "speedup", "efficiency":
+		baselineResult := loadStatResult(baselinePath)
+		if len(baselineResult.Series) > 1 {
+			fmt.Printf("baseline '%s' has %d series, expected a single one\n", baselinePath, len(baselineResult.Series))
+			os.Exit(1)
+		}
+		baseline := PlotEntry{
+			filename:     baselinePath,
+			legendName:   baselinePath,
+			StatResult:   baselineResult,
+			Measurements: baselineResult.Series[0].Measurements,
+		}
+		reportEntries = append([]PlotEntry{baseline}, entriesToPlot...)
+		var err error
+		curves, err = speedupCurves(baseline, entriesToPlot, plotMode == "efficiency")
 		if err != nil {
-			fmt.Println("error adding a point group to", plotEntry.legendName)
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		derivedYLabel = text.speedup
+		if plotMode == "efficiency" {
+			derivedYLabel = text.efficiency
+		}
+	}
+	if len(yLabel) == 0 {
+		yLabel = derivedYLabel
+	}
+
+	chart := Chart{
+		Title:     title,
+		XLabel:    xLabel,
+		YLabel:    yLabel,
+		LogX:      logX,
+		LogY:      logY,
+		YFromZero: plotMode != "time",
+		Curves:    curves,
+		Width:     width,
+		Height:    height,
+	}
+
+	if len(htmlPath) > 0 {
+		err := writeHtmlReport(htmlPath, chart, reportEntries)
+		if err != nil {
+			fmt.Println("error writing the report")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if !imageRequested {
+			return
+		}
 	}
 
-	plot.SavePlot(outPath)
+	render, err := backendFor(backend, outPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	err = render(chart, outPath)
+	if err != nil {
+		fmt.Println("error saving the plot")
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func containsString(s []string, v string) bool {
+	for _, el := range s {
+		if el == v {
+			return true
+		}
+	}
+	return false
 }
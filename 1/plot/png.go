@@ -0,0 +1,175 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// pngCanvas rasterizes shapes with anti-aliasing and draws text with the Go font,
+// which covers both latin and cyrillic labels
+type pngCanvas struct {
+	img   *image.RGBA
+	r     *vector.Rasterizer
+	font  *opentype.Font
+	faces map[float64]font.Face
+}
+
+func newPngCanvas(width, height int) (*pngCanvas, error) {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	return &pngCanvas{
+		img:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		r:     vector.NewRasterizer(width, height),
+		font:  f,
+		faces: make(map[float64]font.Face),
+	}, nil
+}
+
+// colors are not premultiplied, so that translucent fills look the same as in svg
+func uniform(c color.RGBA) *image.Uniform {
+	return image.NewUniform(color.NRGBA(c))
+}
+
+func (p *pngCanvas) fill(points []point, c color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+	b := p.img.Bounds()
+	p.r.Reset(b.Dx(), b.Dy())
+	p.r.MoveTo(float32(points[0].X), float32(points[0].Y))
+	for _, pt := range points[1:] {
+		p.r.LineTo(float32(pt.X), float32(pt.Y))
+	}
+	p.r.ClosePath()
+	p.r.Draw(p.img, b, uniform(c), image.Point{})
+}
+
+// segment draws a line as a quad that is width pixels thick
+func (p *pngCanvas) segment(a, b point, s stroke) {
+	length := math.Hypot(b.X-a.X, b.Y-a.Y)
+	if length == 0 {
+		return
+	}
+	nx, ny := -(b.Y-a.Y)/length*s.Width/2, (b.X-a.X)/length*s.Width/2
+	p.fill([]point{{a.X + nx, a.Y + ny}, {b.X + nx, b.Y + ny}, {b.X - nx, b.Y - ny}, {a.X - nx, a.Y - ny}}, s.Color)
+}
+
+func (p *pngCanvas) Polyline(points []point, s stroke) {
+	const dash, gap = 6.0, 4.0
+
+	// phase is how far into the dash pattern a segment starts,
+	// so that dashing continues from one segment to the next one
+	phase := 0.0
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if !s.Dashed {
+			p.segment(a, b, s)
+			if i > 1 && s.Width > 1 {
+				p.Circle(a, s.Width/2, s.Color)
+			}
+			continue
+		}
+
+		length := math.Hypot(b.X-a.X, b.Y-a.Y)
+		for pos := -phase; pos < length; pos += dash + gap {
+			from, to := math.Max(pos, 0), math.Min(pos+dash, length)
+			if from < to {
+				p.segment(
+					point{a.X + (b.X-a.X)*from/length, a.Y + (b.Y-a.Y)*from/length},
+					point{a.X + (b.X-a.X)*to/length, a.Y + (b.Y-a.Y)*to/length},
+					s,
+				)
+			}
+		}
+		phase = math.Mod(phase+length, dash+gap)
+	}
+}
+
+func (p *pngCanvas) Polygon(points []point, fill color.RGBA) {
+	p.fill(points, fill)
+}
+
+func (p *pngCanvas) Circle(center point, radius float64, fill color.RGBA) {
+	const sides = 20
+	points := make([]point, sides)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / sides
+		points[i] = point{center.X + radius*math.Cos(angle), center.Y + radius*math.Sin(angle)}
+	}
+	p.fill(points, fill)
+}
+
+func (*pngCanvas) Series(int) {}
+
+func (*pngCanvas) Hover(point, string) {}
+
+func (p *pngCanvas) face(size float64) font.Face {
+	face, ok := p.faces[size]
+	if !ok {
+		// a parsed font always makes a face
+		face, _ = opentype.NewFace(p.font, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		p.faces[size] = face
+	}
+	return face
+}
+
+func (p *pngCanvas) Text(at point, text string, size float64, anchor textAnchor, vertical bool, c color.RGBA) {
+	if len(text) == 0 {
+		return
+	}
+	face := p.face(size)
+	width := font.MeasureString(face, text).Ceil()
+	offset := []int{0, width / 2, width}[anchor]
+	x, y := int(math.Round(at.X)), int(math.Round(at.Y))
+
+	if !vertical {
+		d := font.Drawer{Dst: p.img, Src: uniform(c), Face: face, Dot: fixed.P(x-offset, y)}
+		d.DrawString(text)
+		return
+	}
+
+	// vertical text is drawn horizontally into a mask, which is then rotated counterclockwise
+	metrics := face.Metrics()
+	ascent, height := metrics.Ascent.Ceil(), metrics.Ascent.Ceil()+metrics.Descent.Ceil()
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(0, ascent)}
+	d.DrawString(text)
+
+	rotated := image.NewAlpha(image.Rect(0, 0, height, width))
+	for tx := 0; tx < width; tx++ {
+		for ty := 0; ty < height; ty++ {
+			rotated.SetAlpha(ty, width-1-tx, mask.AlphaAt(tx, ty))
+		}
+	}
+
+	bottom := y + offset
+	r := image.Rect(x-ascent, bottom-width, x-ascent+height, bottom)
+	draw.DrawMask(p.img, r, uniform(c), image.Point{}, rotated, image.Point{}, draw.Over)
+}
+
+func renderPng(chart Chart, path string) error {
+	c, err := newPngCanvas(chart.Width, chart.Height)
+	if err != nil {
+		return err
+	}
+	drawChart(c, chart)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, c.img)
+}
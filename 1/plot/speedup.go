@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"parallel-computations/results"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var plotModes = []string{"time", "speedup", "efficiency"}
+
+// flagValue finds a flag in a command the stat file was produced by,
+// it is empty if the flag wasn't passed
+func flagValue(command, name string) string {
+	args := strings.Fields(command)
+	for i, arg := range args {
+		arg = strings.TrimLeft(arg, "-")
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, name+"=") {
+			return strings.TrimPrefix(arg, name+"=")
+		}
+	}
+	return ""
+}
+
+// threadsFromCommand returns 0 if -threads wasn't passed and the thread count is unknown
+func threadsFromCommand(command string) int {
+	threads, _ := strconv.Atoi(flagValue(command, "threads"))
+	return threads
+}
+
+// entryThreads takes the thread count from the series name of a threads sweep,
+// which lab 1 ends with threads=N or sequential, or from the command otherwise
+func entryThreads(entry PlotEntry) int {
+	if strings.HasSuffix(entry.seriesName, " sequential") {
+		return 1
+	}
+	for _, field := range strings.Fields(entry.seriesName) {
+		if strings.HasPrefix(field, "threads=") {
+			threads, _ := strconv.Atoi(strings.TrimPrefix(field, "threads="))
+			return threads
+		}
+	}
+	return threadsFromCommand(entry.Command)
+}
+
+// speedups returns T_seq/T_par of mean times for every size measured both in the baseline
+// and in the entry, sizes where either time is zero are skipped
+func speedups(baseline, entry []results.Measurement) ([]float64, []float64) {
+	baselineTimes := make(map[int]float64, len(baseline))
+	for _, m := range baseline {
+		baselineTimes[m.Size] = m.Mean
+	}
+
+	sizes := make([]float64, 0, len(entry))
+	values := make([]float64, 0, len(entry))
+	for _, m := range entry {
+		seqTime, ok := baselineTimes[m.Size]
+		if !ok || seqTime == 0 || m.Mean == 0 {
+			continue
+		}
+		sizes = append(sizes, float64(m.Size))
+		values = append(values, seqTime/m.Mean)
+	}
+	return sizes, values
+}
+
+// fitAmdahl estimates the serial fraction f of Amdahl's law S = 1/(f + (1-f)/p)
+// from speedups measured with different thread counts by least squares on
+// 1/S - 1/p = f*(1 - 1/p). Speedups of a single thread count would be matched
+// by any law, so ok is false unless there are at least two counts above 1
+func fitAmdahl(threads []int, speedups []float64) (f float64, ok bool) {
+	counts := make(map[int]bool)
+	sxy, sxx := 0.0, 0.0
+	for i, s := range speedups {
+		if threads[i] <= 1 {
+			continue
+		}
+		counts[threads[i]] = true
+		p := float64(threads[i])
+		x := 1 - 1/p
+		sxy += x * (1/s - 1/p)
+		sxx += x * x
+	}
+	if len(counts) < 2 {
+		return 0, false
+	}
+
+	f = sxy / sxx
+	if f < 0 {
+		return 0, true
+	}
+	if f > 1 {
+		return 1, true
+	}
+	return f, true
+}
+
+// fitAmdahlBySize fits a serial fraction for every size
+// measured with at least two thread counts above 1
+func fitAmdahlBySize(sizes, speedups [][]float64, threads []int) map[float64]float64 {
+	sizeThreads := make(map[float64][]int)
+	sizeSpeedups := make(map[float64][]float64)
+	for i := range sizes {
+		for j, size := range sizes[i] {
+			sizeThreads[size] = append(sizeThreads[size], threads[i])
+			sizeSpeedups[size] = append(sizeSpeedups[size], speedups[i][j])
+		}
+	}
+
+	fractions := make(map[float64]float64)
+	for size := range sizeThreads {
+		f, ok := fitAmdahl(sizeThreads[size], sizeSpeedups[size])
+		if ok {
+			fractions[size] = f
+		}
+	}
+	return fractions
+}
+
+func amdahlSpeedup(serialFraction float64, threads int) float64 {
+	return 1 / (serialFraction + (1-serialFraction)/float64(threads))
+}
+
+// speedupCurves plots speedup of every entry against the baseline, or efficiency
+// which is speedup divided by thread count. The serial fraction of Amdahl's law
+// is fitted for every size across entries with different thread counts,
+// and entries with more than 1 thread get the speedup the law predicts for them
+func speedupCurves(baseline PlotEntry, entries []PlotEntry, efficiency bool) ([]Curve, error) {
+	sizes := make([][]float64, len(entries))
+	values := make([][]float64, len(entries))
+	threads := make([]int, len(entries))
+	for i, entry := range entries {
+		sizes[i], values[i] = speedups(baseline.Measurements, entry.Measurements)
+		if len(sizes[i]) == 0 {
+			return nil, fmt.Errorf("'%s' has no sizes in common with the baseline '%s'", entry.filename, baseline.filename)
+		}
+
+		threads[i] = entryThreads(entry)
+		if threads[i] == 0 {
+			if efficiency {
+				return nil, fmt.Errorf("thread count of '%s' is unknown, can't compute efficiency", entry.filename)
+			}
+			fmt.Printf("thread count of '%s' is unknown, it's left out of Amdahl's law fit\n", entry.filename)
+		}
+	}
+
+	fractions := fitAmdahlBySize(sizes, values, threads)
+	if len(fractions) == 0 {
+		fmt.Println("Amdahl's law fit needs entries with at least two thread counts above 1 at the same size")
+	}
+	fittedSizes := make([]float64, 0, len(fractions))
+	for size := range fractions {
+		fittedSizes = append(fittedSizes, size)
+	}
+	sort.Float64s(fittedSizes)
+	for _, size := range fittedSizes {
+		// speedup of a fully parallel program is bounded only by its thread count
+		f := fractions[size]
+		if f > 0 {
+			fmt.Printf("size %g: serial fraction %.3f, maximum speedup %.2f\n", size, f, 1/f)
+		} else {
+			fmt.Printf("size %g: serial fraction %.3f, maximum speedup unbounded\n", size, f)
+		}
+	}
+
+	curves := make([]Curve, 0, 2*len(entries))
+	for i, entry := range entries {
+		fit := Curve{
+			Name:  fmt.Sprintf("%s (%s)", entry.legendName, text.amdahl),
+			Style: "lines",
+			Fit:   true,
+		}
+		if threads[i] > 1 {
+			for _, size := range sizes[i] {
+				if f, ok := fractions[size]; ok {
+					fit.X = append(fit.X, size)
+					fit.Y = append(fit.Y, amdahlSpeedup(f, threads[i]))
+				}
+			}
+		}
+
+		if efficiency {
+			for j := range values[i] {
+				values[i][j] /= float64(threads[i])
+			}
+			for j := range fit.Y {
+				fit.Y[j] /= float64(threads[i])
+			}
+		}
+
+		curves = append(curves, Curve{Name: entry.legendName, Style: "linepoints", X: sizes[i], Y: values[i]})
+		if len(fit.X) > 0 {
+			curves = append(curves, fit)
+		}
+	}
+	return curves, nil
+}
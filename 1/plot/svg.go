@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"os"
+	"strings"
+)
+
+type svgCanvas struct {
+	b strings.Builder
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", c.R, c.G, c.B)
+}
+
+func svgPoints(points []point) string {
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	return strings.Join(coords, " ")
+}
+
+func (s *svgCanvas) Polyline(points []point, st stroke) {
+	dash := ""
+	if st.Dashed {
+		dash = ` stroke-dasharray="6,4"`
+	}
+	fmt.Fprintf(&s.b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%g"%s/>`+"\n",
+		svgPoints(points), svgColor(st.Color), st.Width, dash)
+}
+
+func (s *svgCanvas) Polygon(points []point, fill color.RGBA) {
+	fmt.Fprintf(&s.b, `<polygon points="%s" fill="%s" fill-opacity="%.3f"/>`+"\n",
+		svgPoints(points), svgColor(fill), float64(fill.A)/255)
+}
+
+func (s *svgCanvas) Circle(center point, radius float64, fill color.RGBA) {
+	fmt.Fprintf(&s.b, `<circle cx="%.1f" cy="%.1f" r="%g" fill="%s"/>`+"\n", center.X, center.Y, radius, svgColor(fill))
+}
+
+func (s *svgCanvas) Text(p point, text string, size float64, anchor textAnchor, vertical bool, c color.RGBA) {
+	if len(text) == 0 {
+		return
+	}
+	anchorName := []string{"start", "middle", "end"}[anchor]
+	rotate := ""
+	if vertical {
+		rotate = fmt.Sprintf(` transform="rotate(-90 %.1f %.1f)"`, p.X, p.Y)
+	}
+	fmt.Fprintf(&s.b, `<text x="%.1f" y="%.1f" font-size="%g" text-anchor="%s" fill="%s"%s>%s</text>`+"\n",
+		p.X, p.Y, size, anchorName, svgColor(c), rotate, html.EscapeString(text))
+}
+
+func (*svgCanvas) Series(int) {}
+
+func (*svgCanvas) Hover(point, string) {}
+
+func (s *svgCanvas) String(width, height int) string {
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif">`+"\n%s</svg>\n",
+		width, height, s.b.String())
+}
+
+func renderSvg(chart Chart, path string) error {
+	c := &svgCanvas{}
+	drawChart(c, chart)
+	return os.WriteFile(path, []byte(c.String(chart.Width, chart.Height)), 0644)
+}
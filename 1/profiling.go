@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// MemoryStats are totals over the timed iterations of a single entry,
+// GCPauseTotal is in the output time unit
+type MemoryStats struct {
+	Allocs         uint64
+	AllocatedBytes uint64
+	NumGC          uint32
+	GCPauseTotal   float64
+}
+
+func readMemStats() runtime.MemStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m
+}
+
+func memoryStatsSince(before runtime.MemStats) *MemoryStats {
+	after := readMemStats()
+	return &MemoryStats{
+		Allocs:         after.Mallocs - before.Mallocs,
+		AllocatedBytes: after.TotalAlloc - before.TotalAlloc,
+		NumGC:          after.NumGC - before.NumGC,
+		GCPauseTotal:   float64(after.PauseTotalNs-before.PauseTotalNs) / float64(timeUnit),
+	}
+}
+
+// startProfiling starts cpu profiling and execution tracing for the paths that are set.
+// The returned function stops them and writes a heap profile to memProfilePath
+func startProfiling(cpuProfilePath, memProfilePath, tracePath string) func() {
+	createFile := func(path string) *os.File {
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Println("couldn't create a file at", path)
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return f
+	}
+
+	var cpuProfile, traceFile *os.File
+	if len(cpuProfilePath) > 0 {
+		cpuProfile = createFile(cpuProfilePath)
+		err := pprof.StartCPUProfile(cpuProfile)
+		if err != nil {
+			fmt.Println("couldn't start cpu profiling")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if len(tracePath) > 0 {
+		traceFile = createFile(tracePath)
+		err := trace.Start(traceFile)
+		if err != nil {
+			fmt.Println("couldn't start tracing")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	return func() {
+		if cpuProfile != nil {
+			pprof.StopCPUProfile()
+			cpuProfile.Close()
+		}
+
+		if traceFile != nil {
+			trace.Stop()
+			traceFile.Close()
+		}
+
+		if len(memProfilePath) > 0 {
+			memProfile := createFile(memProfilePath)
+			defer memProfile.Close()
+
+			// up-to-date statistics
+			runtime.GC()
+			err := pprof.WriteHeapProfile(memProfile)
+			if err != nil {
+				fmt.Println("couldn't write a heap profile to", memProfilePath)
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+	}
+}
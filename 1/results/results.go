@@ -0,0 +1,236 @@
+// Package results reads stat files written by lab 1 in any of its output formats
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Time is the mean time rounded to an integer, Mean keeps its full resolution.
+// Files written before Mean was recorded get their Time as Mean
+type Measurement struct {
+	Size    int
+	Time    int
+	Mean    float64
+	Samples []float64
+}
+
+func (m *Measurement) fillMean() {
+	if m.Mean == 0 {
+		m.Mean = float64(m.Time)
+	}
+}
+
+// Name of the only series of a file that stats a single operation is empty
+type Series struct {
+	Name         string
+	Measurements []Measurement `json:"Entries"`
+}
+
+// TimeUnit is empty when the file doesn't record it,
+// numbers in Environment are kept as json.Number
+type StatResult struct {
+	Command     string
+	Environment map[string]interface{}
+	TimeUnit    string
+	Series      []Series
+}
+
+// Find returns the series with the given name
+func (r *StatResult) Find(name string) (Series, bool) {
+	for _, s := range r.Series {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Series{}, false
+}
+
+// Load decodes a stat file in the format its extension points to
+func Load(path string) (*StatResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	res, err := Decode(f, FileFormat(path))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return res, nil
+}
+
+// FileFormat picks a format based on the file extension, json is the default
+func FileFormat(path string) string {
+	switch filepath.Ext(path) {
+	case ".csv":
+		return "csv"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+	return "json"
+}
+
+// Decode reads a stat file in one of json, csv and ndjson formats,
+// series are kept in the order they appear in the file
+func Decode(r io.Reader, format string) (*StatResult, error) {
+	var res *StatResult
+	var err error
+	switch format {
+	case "csv":
+		res, err = decodeCsv(r)
+	case "ndjson":
+		res, err = decodeNdjson(r)
+	default:
+		res, err = decodeJson(r)
+	}
+	if err == nil && len(res.Series) == 0 {
+		err = fmt.Errorf("no measurements")
+	}
+	return res, err
+}
+
+func decodeJson(r io.Reader) (*StatResult, error) {
+	var raw struct {
+		StatResult
+		Entries []Measurement
+	}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw.Entries) > 0 {
+		raw.Series = append([]Series{{Measurements: raw.Entries}}, raw.Series...)
+	}
+	for _, series := range raw.Series {
+		for i := range series.Measurements {
+			series.Measurements[i].fillMean()
+		}
+	}
+	return &raw.StatResult, nil
+}
+
+// seriesBuilder appends measurements to series by name
+type seriesBuilder struct {
+	series []Series
+	index  map[string]int
+}
+
+func (b *seriesBuilder) add(name string, m Measurement) {
+	if b.index == nil {
+		b.index = make(map[string]int)
+	}
+	i, ok := b.index[name]
+	if !ok {
+		i = len(b.series)
+		b.index[name] = i
+		b.series = append(b.series, Series{Name: name})
+	}
+	b.series[i].Measurements = append(b.series[i].Measurements, m)
+}
+
+// decodeNdjson reads records with a single decoder, lines with all samples
+// of long runs don't fit into a bufio.Scanner's buffer
+func decodeNdjson(r io.Reader) (*StatResult, error) {
+	res := &StatResult{}
+	series := seriesBuilder{}
+
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for i := 1; decoder.More(); i++ {
+		var record struct {
+			Command     string
+			Environment map[string]interface{}
+			TimeUnit    string
+			Series      string
+			Derived     string
+			Measurement
+		}
+		err := decoder.Decode(&record)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+
+		switch {
+		case len(record.Command) > 0:
+			res.Command = record.Command
+			res.Environment = record.Environment
+			res.TimeUnit = record.TimeUnit
+		case len(record.Derived) > 0:
+			continue
+		default:
+			record.Measurement.fillMean()
+			series.add(record.Series, record.Measurement)
+		}
+	}
+
+	res.Series = series.series
+	return res, nil
+}
+
+func decodeCsv(r io.Reader) (*StatResult, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("csv has no header")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	for _, name := range []string{"Series", "Size", "Time"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv has no '%s' column", name)
+		}
+	}
+
+	res := &StatResult{}
+	series := seriesBuilder{}
+	for i, record := range records[1:] {
+		size, err := strconv.Atoi(record[columns["Size"]])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		time, err := strconv.Atoi(record[columns["Time"]])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		m := Measurement{Size: size, Time: time}
+		if column, ok := columns["Mean"]; ok && len(record[column]) > 0 {
+			m.Mean, err = strconv.ParseFloat(record[column], 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+2, err)
+			}
+		}
+		m.fillMean()
+		if column, ok := columns["Samples"]; ok {
+			for _, v := range strings.Fields(record[column]) {
+				sample, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: %w", i+2, err)
+				}
+				m.Samples = append(m.Samples, sample)
+			}
+		}
+		if column, ok := columns["TimeUnit"]; ok {
+			res.TimeUnit = record[column]
+		}
+		series.add(record[columns["Series"]], m)
+	}
+
+	res.Series = series.series
+	return res, nil
+}
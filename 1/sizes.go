@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var sizeScales = []string{"linear", "log", "pow2pm1"}
+
+// SizeSequence produces the matrix sizes a stat sweep goes through
+type SizeSequence struct {
+	Scale  string
+	Start  int
+	End    int
+	Step   int
+	Factor float64
+	// Explicit sizes override everything else
+	Sizes []int
+}
+
+func parseSizes(sizes string) ([]int, error) {
+	split := strings.Split(sizes, ",")
+	res := make([]int, 0, len(split))
+	for _, v := range split {
+		size, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid size '%s'", v)
+		}
+		res = append(res, size)
+	}
+
+	// sweeps go from small to big sizes without repeating them
+	sort.Ints(res)
+	unique := res[:1]
+	for _, size := range res[1:] {
+		if size != unique[len(unique)-1] {
+			unique = append(unique, size)
+		}
+	}
+	return unique, nil
+}
+
+func (s SizeSequence) Validate() error {
+	if len(s.Sizes) > 0 {
+		return nil
+	}
+	if s.Start <= 0 || s.End < s.Start {
+		return fmt.Errorf("sizes have to go from a positive start to an end that isn't smaller than it")
+	}
+	switch s.Scale {
+	case "linear":
+		if s.Step <= 0 {
+			return fmt.Errorf("step has to be positive")
+		}
+	case "log":
+		if s.Factor <= 1 {
+			return fmt.Errorf("factor has to be greater than 1")
+		}
+	case "pow2pm1":
+	default:
+		return fmt.Errorf("unknown scale '%s', expected one of %s", s.Scale, strings.Join(sizeScales, "|"))
+	}
+	if len(s.Generate()) == 0 {
+		return fmt.Errorf("there are no %s sizes between %d and %d", s.Scale, s.Start, s.End)
+	}
+	return nil
+}
+
+func (s SizeSequence) Generate() []int {
+	if len(s.Sizes) > 0 {
+		return s.Sizes
+	}
+
+	sizes := make([]int, 0)
+	switch s.Scale {
+	case "linear":
+		for size := s.Start; size <= s.End; size += s.Step {
+			sizes = append(sizes, size)
+		}
+	case "log":
+		for size := s.Start; size <= s.End; {
+			sizes = append(sizes, size)
+			next := int(math.Round(float64(size) * s.Factor))
+			if next <= size {
+				next = size + 1
+			}
+			size = next
+		}
+	case "pow2pm1":
+		// 2^k-1, 2^k and 2^k+1 for every power of two in [Start; End]
+		for p := 1; p <= s.End+1; p *= 2 {
+			for _, size := range []int{p - 1, p, p + 1} {
+				if size >= s.Start && size <= s.End && (len(sizes) == 0 || size > sizes[len(sizes)-1]) {
+					sizes = append(sizes, size)
+				}
+			}
+		}
+	}
+	return sizes
+}
+
+// String describes the sequence for output file names
+func (s SizeSequence) String() string {
+	if len(s.Sizes) > 0 {
+		split := make([]string, len(s.Sizes))
+		for i, size := range s.Sizes {
+			split[i] = strconv.Itoa(size)
+		}
+		return "sizes=" + strings.Join(split, "+")
+	}
+
+	switch s.Scale {
+	case "log":
+		return fmt.Sprintf("from=%d_to=%d_factor=%g", s.Start, s.End, s.Factor)
+	case "pow2pm1":
+		return fmt.Sprintf("from=%d_to=%d_pow2pm1", s.Start, s.End)
+	}
+	return fmt.Sprintf("from=%d_to=%d_step=%d", s.Start, s.End, s.Step)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"parallel-computations/results"
+	"strconv"
+	"text/tabwriter"
+)
+
+var (
+	threshold float64
+	alpha     float64
+)
+
+// exit code when a regression is found, errors exit with 1
+const regressionExitCode = 2
+
+// parseFlags isn't run in init, so that go test can pass its own flags
+func parseFlags() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: statdiff [flags] baseline candidate [candidate...]")
+		fmt.Fprintln(flag.CommandLine.Output(), "compares every candidate stat file against the baseline, "+
+			"exits with", regressionExitCode, "if a regression is found")
+		flag.PrintDefaults()
+	}
+	flag.Float64Var(&threshold, "threshold", 5, "slowdown in percent above which a significant change is a regression")
+	flag.Float64Var(&alpha, "alpha", 0.05, "significance level of Welch's t-test")
+	printHelp := flag.Bool("help", false, "print help")
+	flag.Parse()
+
+	if *printHelp {
+		flag.Usage()
+		os.Exit(0)
+	}
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if alpha <= 0 || alpha >= 1 {
+		fmt.Println("alpha has to be between 0 and 1")
+		os.Exit(1)
+	}
+}
+
+// measurementValues are the samples of a measurement or its mean if there are too few of them
+func measurementValues(m results.Measurement) []float64 {
+	if len(m.Samples) >= 2 {
+		return m.Samples
+	}
+	return []float64{m.Mean}
+}
+
+type comparison struct {
+	size      int
+	baseline  float64
+	candidate float64
+	change    float64
+	// NaN when either of the files doesn't have enough samples
+	pValue float64
+}
+
+func (c comparison) significant() bool {
+	return math.IsNaN(c.pValue) || c.pValue < alpha
+}
+
+// verdict of a comparison without samples is based on the change alone
+func (c comparison) verdict() string {
+	switch {
+	case c.change > threshold && c.significant():
+		return "regression"
+	case c.change < -threshold && c.significant():
+		return "improvement"
+	}
+	return ""
+}
+
+func compare(baseline, candidate []results.Measurement) []comparison {
+	baselineMeasurements := make(map[int]results.Measurement, len(baseline))
+	for _, m := range baseline {
+		baselineMeasurements[m.Size] = m
+	}
+
+	comparisons := make([]comparison, 0, len(candidate))
+	for _, m := range candidate {
+		b, ok := baselineMeasurements[m.Size]
+		if !ok {
+			continue
+		}
+
+		baselineValues, candidateValues := measurementValues(b), measurementValues(m)
+		c := comparison{
+			size:      m.Size,
+			baseline:  b.Mean,
+			candidate: m.Mean,
+			pValue:    math.NaN(),
+		}
+		if c.baseline != 0 {
+			c.change = (c.candidate - c.baseline) / c.baseline * 100
+		}
+		if len(baselineValues) >= 2 && len(candidateValues) >= 2 {
+			c.pValue = welchTest(baselineValues, candidateValues)
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons
+}
+
+// baselineSeries finds a series of the same name, files with a single series
+// are compared regardless of their names
+func baselineSeries(baseline, candidate *results.StatResult, series results.Series) (results.Series, bool) {
+	if len(baseline.Series) == 1 && len(candidate.Series) == 1 {
+		return baseline.Series[0], true
+	}
+	return baseline.Find(series.Name)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// printComparisons prints a table of comparisons and returns the number of regressions
+func printComparisons(comparisons []comparison) int {
+	if len(comparisons) == 0 {
+		fmt.Println("no sizes in common")
+		fmt.Println()
+		return 0
+	}
+
+	regressions := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "size\tbaseline\tcandidate\tchange\tp-value\t\t")
+	for _, c := range comparisons {
+		pValue := "n/a"
+		if !math.IsNaN(c.pValue) {
+			pValue = strconv.FormatFloat(c.pValue, 'f', 4, 64)
+		}
+		verdict := c.verdict()
+		if verdict == "regression" {
+			regressions++
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%+.2f%%\t%s\t%s\t\n",
+			c.size, formatFloat(c.baseline), formatFloat(c.candidate), c.change, pValue, verdict)
+	}
+	w.Flush()
+	fmt.Println()
+	return regressions
+}
+
+func main() {
+	parseFlags()
+
+	baselinePath := flag.Arg(0)
+	baseline, err := results.Load(baselinePath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	regressions := 0
+	for _, candidatePath := range flag.Args()[1:] {
+		candidate, err := results.Load(candidatePath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(baseline.TimeUnit) > 0 && len(candidate.TimeUnit) > 0 && baseline.TimeUnit != candidate.TimeUnit {
+			fmt.Printf("'%s' is in %s while '%s' is in %s\n", baselinePath, baseline.TimeUnit, candidatePath, candidate.TimeUnit)
+			os.Exit(1)
+		}
+
+		for _, series := range candidate.Series {
+			name := candidatePath
+			if len(series.Name) > 0 {
+				name += " " + series.Name
+			}
+			fmt.Printf("%s -> %s\n", baselinePath, name)
+
+			b, ok := baselineSeries(baseline, candidate, series)
+			if !ok {
+				fmt.Println("the baseline has no such series")
+				fmt.Println()
+				continue
+			}
+			regressions += printComparisons(compare(b.Measurements, series.Measurements))
+		}
+	}
+
+	if regressions > 0 {
+		fmt.Printf("%d regressions slower by more than %g%%\n", regressions, threshold)
+		os.Exit(regressionExitCode)
+	}
+	fmt.Println("no regressions")
+}
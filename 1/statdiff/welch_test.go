@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func closeTo(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// closed forms of I_x(a, b) for small or equal parameters
+func TestRegularizedIncompleteBeta(t *testing.T) {
+	for _, x := range []float64{0, 0.01, 0.2, 0.5, 0.73, 0.99, 1} {
+		tests := []struct {
+			a, b     float64
+			expected float64
+		}{
+			{1, 1, x},
+			{3, 1, math.Pow(x, 3)},
+			{1, 4, 1 - math.Pow(1-x, 4)},
+			{2, 2, x * x * (3 - 2*x)},
+		}
+		for _, test := range tests {
+			got := regularizedIncompleteBeta(test.a, test.b, x)
+			if !closeTo(got, test.expected, 1e-10) {
+				t.Errorf("I_%g(%g, %g) = %g, expected %g", x, test.a, test.b, got, test.expected)
+			}
+		}
+	}
+
+	for _, a := range []float64{0.5, 2.5, 10, 100} {
+		got := regularizedIncompleteBeta(a, a, 0.5)
+		if !closeTo(got, 0.5, 1e-10) {
+			t.Errorf("I_0.5(%g, %g) = %g, expected 0.5", a, a, got)
+		}
+	}
+}
+
+// Two-sided 95% quantiles of Student's t-distribution from statistical tables
+func TestTQuantiles(t *testing.T) {
+	quantiles := map[float64]float64{
+		1:   12.7062047,
+		2:   4.3026527,
+		5:   2.5705818,
+		10:  2.2281389,
+		30:  2.0422725,
+		100: 1.9839715,
+	}
+	for df, q := range quantiles {
+		p := regularizedIncompleteBeta(df/2, 0.5, df/(df+q*q))
+		if !closeTo(p, 0.05, 1e-6) {
+			t.Errorf("p-value of t = %g with %g degrees of freedom is %g, expected 0.05", q, df, p)
+		}
+	}
+}
+
+func TestWelchTest(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []float64
+		expected float64
+	}{
+		// equal variances and sizes: t = -1/sqrt(2) with 2 degrees of freedom,
+		// where p = 1 - |t|/sqrt(2 + t^2)
+		{"equal variances", []float64{0, 2}, []float64{1, 3}, 1 - 1/math.Sqrt(5)},
+		// b has no variance, so there is 1 degree of freedom and t = -4,
+		// where p = 1 - 2/pi*atan(|t|)
+		{"one degree of freedom", []float64{0, 2}, []float64{5, 5, 5}, 1 - 2/math.Pi*math.Atan(4)},
+		// t = -1000/sqrt(2) with 2 degrees of freedom
+		{"extreme t", []float64{0, 2}, []float64{1000, 1002}, 1 - (1000/math.Sqrt2)/math.Sqrt(2+500000)},
+		{"equal means", []float64{1, 2, 3, 4}, []float64{4, 3, 2, 1}, 1},
+		{"identical samples", []float64{7, 7, 7}, []float64{7, 7}, 1},
+		{"constant different samples", []float64{7, 7, 7}, []float64{8, 8}, 0},
+	}
+
+	for _, test := range tests {
+		got := welchTest(test.a, test.b)
+		if !closeTo(got, test.expected, 1e-9) {
+			t.Errorf("%s: p = %g, expected %g", test.name, got, test.expected)
+		}
+		if reversed := welchTest(test.b, test.a); !closeTo(got, reversed, 1e-12) {
+			t.Errorf("%s: p = %g with samples swapped, expected %g", test.name, reversed, got)
+		}
+	}
+}
+
+func TestWelchTestExtremeT(t *testing.T) {
+	a := []float64{1, 1.0001, 0.9999, 1, 1.0002, 0.9998}
+	b := []float64{2, 2.0001, 1.9999, 2, 2.0002, 1.9998}
+	p := welchTest(a, b)
+	if math.IsNaN(p) || p < 0 || p > 1e-12 {
+		t.Errorf("p = %g, expected a tiny non-negative value", p)
+	}
+}
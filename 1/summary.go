@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary of timing samples in the output time unit.
+// CI95 is the half-width of the 95% confidence interval of the mean
+type Summary struct {
+	Min    float64
+	Max    float64
+	Mean   float64
+	Median float64
+	P95    float64
+	StdDev float64
+	CI95   float64
+}
+
+func warmUp(f func(), iterations int) {
+	for i := 0; i < iterations; i++ {
+		f()
+	}
+}
+
+// timeSamples times every iteration of f separately
+func timeSamples(f func(), iterations int) []time.Duration {
+	samples := make([]time.Duration, iterations)
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		f()
+		samples[i] = time.Since(start)
+	}
+	return samples
+}
+
+// adaptiveSamples times iterations of f until the confidence interval of the mean
+// gets narrower than ciTarget relative to the mean, sizeBudget is spent or deadline
+// is reached. At least minIterations are done unless the deadline is reached
+func adaptiveSamples(f func(), minIterations int, ciTarget float64, sizeBudget time.Duration, deadline time.Time) []time.Duration {
+	start := time.Now()
+	samples := make([]time.Duration, 0, minIterations)
+	for {
+		sampleStart := time.Now()
+		f()
+		samples = append(samples, time.Since(sampleStart))
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return samples
+		}
+		if len(samples) < minIterations || len(samples) < 2 {
+			continue
+		}
+		if time.Since(start) > sizeBudget {
+			return samples
+		}
+
+		mean, halfWidth := meanConfidenceInterval(samples)
+		if mean > 0 && halfWidth/mean < ciTarget {
+			return samples
+		}
+	}
+}
+
+// Two-sided 95% quantiles of Student's t-distribution for 1..30 degrees of freedom
+var tQuantiles95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func tQuantile95(degreesOfFreedom int) float64 {
+	if degreesOfFreedom < 1 {
+		return math.Inf(1)
+	}
+	if degreesOfFreedom > len(tQuantiles95) {
+		return 1.96
+	}
+	return tQuantiles95[degreesOfFreedom-1]
+}
+
+// meanConfidenceInterval returns the mean and the half-width of its 95% confidence interval
+func meanConfidenceInterval(samples []time.Duration) (float64, float64) {
+	var total time.Duration
+	for _, v := range samples {
+		total += v
+	}
+	mean := float64(total) / float64(len(samples))
+
+	variance := 0.0
+	for _, v := range samples {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+	if len(samples) < 2 {
+		return mean, math.Inf(1)
+	}
+	variance /= float64(len(samples) - 1)
+
+	return mean, tQuantile95(len(samples)-1) * math.Sqrt(variance/float64(len(samples)))
+}
+
+func toTimeUnit(d time.Duration) float64 {
+	return float64(d) / float64(timeUnit)
+}
+
+func summarize(samples []time.Duration) Summary {
+	if len(samples) == 0 {
+		return Summary{}
+	}
+
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mean, halfWidth := meanConfidenceInterval(sorted)
+	if len(sorted) < 2 {
+		// a single sample says nothing about the spread
+		halfWidth = 0
+	}
+
+	variance := 0.0
+	for _, v := range sorted {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+	if len(sorted) > 1 {
+		variance /= float64(len(sorted) - 1)
+	}
+
+	mid := len(sorted) / 2
+	median := float64(sorted[mid])
+	if len(sorted)%2 == 0 {
+		median = float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+
+	// nearest-rank percentile
+	p95 := sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+
+	unit := float64(timeUnit)
+	return Summary{
+		Min:    toTimeUnit(sorted[0]),
+		Max:    toTimeUnit(sorted[len(sorted)-1]),
+		Mean:   mean / unit,
+		Median: median / unit,
+		P95:    toTimeUnit(p95),
+		StdDev: math.Sqrt(variance) / unit,
+		CI95:   halfWidth / unit,
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// parseThreadsSweep accepts a comma-delimited list of thread counts or "auto",
+// which means powers of two up to GOMAXPROCS and GOMAXPROCS itself
+func parseThreadsSweep(sweep string) ([]uint, error) {
+	if sweep == "auto" {
+		maxProcs := uint(runtime.GOMAXPROCS(0))
+		threads := make([]uint, 0)
+		for t := uint(1); t < maxProcs; t *= 2 {
+			threads = append(threads, t)
+		}
+		return append(threads, maxProcs), nil
+	}
+
+	split := strings.Split(sweep, ",")
+	threads := make([]uint, 0, len(split))
+	for _, v := range split {
+		t, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+		if err != nil || t == 0 {
+			return nil, fmt.Errorf("invalid thread count '%s' in threads sweep", v)
+		}
+		threads = append(threads, uint(t))
+	}
+	return threads, nil
+}
+
+type DerivedEntry struct {
+	Size  int
+	Value float64
+}
+
+type DerivedSeries struct {
+	Name    string
+	Entries []DerivedEntry
+}
+
+// deriveSpeedup compares parallel series against a sequential baseline of the same
+// operation. Speedup is T_seq/T_par and efficiency is speedup divided by thread count
+func deriveSpeedup(baseline, parallel Series, threads uint) (DerivedSeries, DerivedSeries) {
+	speedup := DerivedSeries{Name: parallel.Name + " speedup"}
+	efficiency := DerivedSeries{Name: parallel.Name + " efficiency"}
+
+	baselineMeans := make(map[int]float64, len(baseline.Entries))
+	for _, e := range baseline.Entries {
+		baselineMeans[e.Size] = e.Mean
+	}
+
+	for _, e := range parallel.Entries {
+		seqMean, ok := baselineMeans[e.Size]
+		if !ok || e.Mean == 0 {
+			continue
+		}
+		s := seqMean / e.Mean
+		speedup.Entries = append(speedup.Entries, DerivedEntry{e.Size, s})
+		efficiency.Entries = append(efficiency.Entries, DerivedEntry{e.Size, s / float64(threads)})
+	}
+
+	return speedup, efficiency
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Action is what happens to a process a route can't send to a cpu right away
+type Action string
+
+const (
+	// try the next cpu of the route, only for Route.OnForeign
+	actionNext    Action = "next"
+	actionQueue   Action = "queue"
+	actionLose    Action = "lose"
+	actionDestroy Action = "destroy"
+)
+
+func (a Action) apply(s *Scheduler, p Process) {
+	switch a {
+	case actionLose:
+		s.Lose(p)
+	case actionDestroy:
+		s.Destroy(p)
+	default:
+		s.Enqueue(p)
+	}
+}
+
+// Route sends processes of a generator to the first free of Cpus.
+// OnForeign is applied when the first cpu is busy with a process of another generator,
+// OnBusy when all cpus are busy
+type Route struct {
+	Generator int
+	Cpus      []int
+	OnForeign Action
+	OnBusy    Action
+}
+
+// GeneratorConfig generates Processes processes every Min to Max milliseconds
+type GeneratorConfig struct {
+	Processes int
+	Min       int
+	Max       int
+	Priority  int
+}
+
+// CpuConfig processes a process in Min to Max milliseconds depending on its size
+type CpuConfig struct {
+	Min int
+	Max int
+}
+
+// Config of a simulation, ids of generators and cpus are their indices + 1
+type Config struct {
+	Generators []GeneratorConfig
+	Cpus       []CpuConfig
+	Routes     []Route
+}
+
+// defaultConfig is the setup of the lab assignment
+func defaultConfig() Config {
+	return Config{
+		Generators: []GeneratorConfig{
+			{Processes: 15, Min: 50, Max: 300, Priority: 2},
+			{Processes: 15, Min: 50, Max: 300, Priority: 1},
+		},
+		Cpus: []CpuConfig{
+			{Min: 60, Max: 200},
+			{Min: 30, Max: 100},
+		},
+		Routes: []Route{
+			{Generator: 1, Cpus: []int{1, 2}, OnForeign: actionLose, OnBusy: actionDestroy},
+			{Generator: 2, Cpus: []int{2}, OnForeign: actionNext, OnBusy: actionQueue},
+		},
+	}
+}
+
+func loadConfig(path string) (Config, error) {
+	var config Config
+	f, err := os.Open(path)
+	if err != nil {
+		return config, err
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&config)
+	if err != nil {
+		return config, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// validate fills in default actions of routes
+func (c *Config) validate() error {
+	if len(c.Generators) == 0 {
+		return fmt.Errorf("no generators")
+	}
+	if len(c.Cpus) == 0 {
+		return fmt.Errorf("no cpus")
+	}
+	for i, g := range c.Generators {
+		if g.Processes < 0 || g.Min < 0 || g.Max <= g.Min {
+			return fmt.Errorf("generator %d: expected 0 <= min < max and a non-negative number of processes", i+1)
+		}
+	}
+	for i, cpu := range c.Cpus {
+		if cpu.Min < 0 || cpu.Max < cpu.Min {
+			return fmt.Errorf("cpu %d: expected 0 <= min <= max", i+1)
+		}
+	}
+
+	routed := make(map[int]bool)
+	for i := range c.Routes {
+		r := &c.Routes[i]
+		if r.Generator < 1 || r.Generator > len(c.Generators) {
+			return fmt.Errorf("route %d: no generator %d", i+1, r.Generator)
+		}
+		if routed[r.Generator] {
+			return fmt.Errorf("route %d: generator %d already has a route", i+1, r.Generator)
+		}
+		routed[r.Generator] = true
+		if len(r.Cpus) == 0 {
+			return fmt.Errorf("route %d: no cpus", i+1)
+		}
+		for _, id := range r.Cpus {
+			if id < 1 || id > len(c.Cpus) {
+				return fmt.Errorf("route %d: no cpu %d", i+1, id)
+			}
+		}
+
+		if len(r.OnForeign) == 0 {
+			r.OnForeign = actionNext
+		}
+		if len(r.OnBusy) == 0 {
+			r.OnBusy = actionQueue
+		}
+		switch r.OnForeign {
+		case actionNext, actionQueue, actionLose, actionDestroy:
+		default:
+			return fmt.Errorf("route %d: unknown action '%s'", i+1, r.OnForeign)
+		}
+		switch r.OnBusy {
+		case actionQueue, actionLose, actionDestroy:
+		default:
+			return fmt.Errorf("route %d: unknown action '%s'", i+1, r.OnBusy)
+		}
+	}
+	return nil
+}
+
+func parseInts(s string) ([]int, error) {
+	fields := strings.Split(s, ":")
+	res := make([]int, len(fields))
+	for i, field := range fields {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// generatorFlags is a repeated flag of generators in the form processes:min:max[:priority]
+type generatorFlags []GeneratorConfig
+
+func (g *generatorFlags) String() string {
+	return fmt.Sprint(*g)
+}
+
+func (g *generatorFlags) Set(s string) error {
+	v, err := parseInts(s)
+	if err != nil {
+		return err
+	}
+	if len(v) != 3 && len(v) != 4 {
+		return fmt.Errorf("expected processes:min:max[:priority]")
+	}
+	generator := GeneratorConfig{Processes: v[0], Min: v[1], Max: v[2]}
+	if len(v) == 4 {
+		generator.Priority = v[3]
+	}
+	*g = append(*g, generator)
+	return nil
+}
+
+// cpuFlags is a repeated flag of cpus in the form min:max
+type cpuFlags []CpuConfig
+
+func (c *cpuFlags) String() string {
+	return fmt.Sprint(*c)
+}
+
+func (c *cpuFlags) Set(s string) error {
+	v, err := parseInts(s)
+	if err != nil {
+		return err
+	}
+	if len(v) != 2 {
+		return fmt.Errorf("expected min:max")
+	}
+	*c = append(*c, CpuConfig{Min: v[0], Max: v[1]})
+	return nil
+}
+
+// routeFlags is a repeated flag of routes in the form generator:cpu[,cpu...][:onBusy[:onForeign]]
+type routeFlags []Route
+
+func (r *routeFlags) String() string {
+	return fmt.Sprint(*r)
+}
+
+func (r *routeFlags) Set(s string) error {
+	fields := strings.Split(s, ":")
+	if len(fields) < 2 || len(fields) > 4 {
+		return fmt.Errorf("expected generator:cpu[,cpu...][:onBusy[:onForeign]]")
+	}
+
+	generator, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return err
+	}
+	route := Route{Generator: generator}
+	for _, field := range strings.Split(fields[1], ",") {
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return err
+		}
+		route.Cpus = append(route.Cpus, id)
+	}
+	if len(fields) > 2 {
+		route.OnBusy = Action(fields[2])
+	}
+	if len(fields) > 3 {
+		route.OnForeign = Action(fields[3])
+	}
+	*r = append(*r, route)
+	return nil
+}
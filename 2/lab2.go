@@ -21,6 +21,8 @@ type Statistics struct {
 	LostProcesses      int
 	DestroyedProcesses int
 	MaxQueueLength     int
+	// time processes spent between being generated and starting to run
+	TotalWaitTime time.Duration
 
 	curQueueLength int
 	mutex          sync.Mutex
@@ -48,11 +50,32 @@ func (s *Statistics) Print() {
 	fmt.Printf("  Lost      %d\t%f%%\n", s.LostProcesses, float32(s.LostProcesses)/float32(s.TotalProcesses)*100)
 	fmt.Printf("  Destroyed %d\t%f%%\n", s.DestroyedProcesses, float32(s.DestroyedProcesses)/float32(s.TotalProcesses)*100)
 	fmt.Println("Max queue length:", s.MaxQueueLength)
+	if s.FinishedProcesses > 0 {
+		fmt.Println("Average waiting time:", s.TotalWaitTime/time.Duration(s.FinishedProcesses))
+	}
+}
+
+// deriveSeed mixes stream into seed, so that every goroutine gets
+// its own reproducible sequence of random numbers
+func deriveSeed(seed int64, stream int64) int64 {
+	z := uint64(seed) + uint64(stream)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
 }
 
+const (
+	generatorSeedStream = 1
+)
+
+// Size is how much work a process takes, from 0 to 1 of a cpu's processing time range.
+// It's known beforehand, so that policies can pick short processes first
 type Process struct {
-	ParentId int
-	Id       int
+	ParentId  int
+	Id        int
+	Size      float64
+	Priority  int
+	CreatedAt time.Time
 }
 
 type ProcessGenerator struct {
@@ -63,11 +86,15 @@ type ProcessGenerator struct {
 	MinProcessGenerationTime int
 	MaxProcessGenerationTime int
 
-	Wg *sync.WaitGroup
+	// priority of generated processes, the higher the sooner they are run by the priority policy
+	Priority int
+
+	Seed int64
+	Wg   *sync.WaitGroup
 }
 
 func (p *ProcessGenerator) Run() {
-	randSource := rand.NewSource(time.Now().UnixNano())
+	randSource := rand.NewSource(p.Seed)
 	rand := *rand.New(randSource)
 
 	for i := 0; i < p.ProcessesToGenerate; i++ {
@@ -76,8 +103,11 @@ func (p *ProcessGenerator) Run() {
 		time.Sleep(time.Millisecond * time.Duration(timeToWait))
 
 		process := Process{
-			ParentId: p.Id,
-			Id:       i,
+			ParentId:  p.Id,
+			Id:        i,
+			Size:      rand.Float64(),
+			Priority:  p.Priority,
+			CreatedAt: time.Now(),
 		}
 		formatLog("GEN%d: ==> %d_%d\n", process.ParentId, process.ParentId, process.Id)
 
@@ -87,13 +117,13 @@ func (p *ProcessGenerator) Run() {
 	p.Wg.Done()
 }
 
+// Cpu runs processes the scheduler sends to DirectQueue one at a time
+// and reports to Finished when it's done with one
 type Cpu struct {
-	Id           int
-	GeneralQueue chan Process
-	DirectQueue  chan Process
-	Wg           *sync.WaitGroup
-
-	*Statistics
+	Id          int
+	DirectQueue chan Process
+	Finished    chan<- *Cpu
+	Wg          *sync.WaitGroup
 
 	IsBusy          bool
 	CurProcess      Process
@@ -104,43 +134,18 @@ type Cpu struct {
 }
 
 func (c *Cpu) Run() {
-	randSource := rand.NewSource(time.Now().UnixNano())
-	rand := *rand.New(randSource)
-
-	runProcess := func(p Process) {
-		c.CurProcessMutex.Lock()
-		c.IsBusy = true
-		c.CurProcess = p
-		c.CurProcessMutex.Unlock()
-
+	for p := range c.DirectQueue {
 		formatLog("CPU%d: <== %d_%d\n", c.Id, p.ParentId, p.Id)
 
 		// simulate activity
-		timeToWait := (rand.Int() % (c.MaxProcessingTime - c.MinProcessingTime)) + c.MinProcessingTime
+		timeToWait := c.MinProcessingTime + int(p.Size*float64(c.MaxProcessingTime-c.MinProcessingTime))
 		time.Sleep(time.Millisecond * time.Duration(timeToWait))
 
 		formatLog("CPU%d: finished %d_%d\n", c.Id, p.ParentId, p.Id)
-
-		c.CurProcessMutex.Lock()
-		c.IsBusy = false
-		c.CurProcessMutex.Unlock()
+		c.Finished <- c
 	}
 
-	var process Process
-	for {
-		select {
-		case p, ok := <-c.GeneralQueue:
-			if !ok {
-				c.Wg.Done()
-				return
-			}
-			process = p
-			c.ChangeQueueLength(-1)
-		case process = <-c.DirectQueue:
-		}
-
-		runProcess(process)
-	}
+	c.Wg.Done()
 }
 
 func (c *Cpu) GetCurrentProcess() (Process, bool) {
@@ -149,18 +154,112 @@ func (c *Cpu) GetCurrentProcess() (Process, bool) {
 	return c.CurProcess, c.IsBusy
 }
 
+func (c *Cpu) setCurrentProcess(p Process, busy bool) {
+	c.CurProcessMutex.Lock()
+	defer c.CurProcessMutex.Unlock()
+	c.CurProcess = p
+	c.IsBusy = busy
+}
+
+// Scheduler hands processes to cpus according to Policy. A cpu is busy from the moment
+// it's given a process until the scheduler gets its report on Finished, so that only
+// the scheduler decides which cpus are free
 type Scheduler struct {
-	Gen1  *ProcessGenerator
-	Gen2  *ProcessGenerator
-	GenWg *sync.WaitGroup
+	Generators []*ProcessGenerator
+	GenWg      *sync.WaitGroup
 
-	Cpu1 *Cpu
-	Cpu2 *Cpu
+	// Id of a cpu is its index + 1
+	Cpus []*Cpu
 
 	*Statistics
 
+	Policy SchedulingPolicy
+	// routes of the lab policy by generator id
+	Routes map[int]Route
+
 	SchedulerQueue chan Process
-	CpuQueue       chan Process
+	Finished       chan *Cpu
+
+	// processes waiting for a free cpu, the queue is unlimited if QueueCapacity is 0
+	Queue         []Process
+	QueueCapacity int
+}
+
+func (s *Scheduler) Cpu(id int) *Cpu {
+	return s.Cpus[id-1]
+}
+
+// FreeCpu returns the first free cpu starting from the one at index from, or nil if all are busy
+func (s *Scheduler) FreeCpu(from int) *Cpu {
+	for i := range s.Cpus {
+		c := s.Cpus[(from+i)%len(s.Cpus)]
+		if _, busy := c.GetCurrentProcess(); !busy {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) Dispatch(c *Cpu, p Process) {
+	formatLog("SCHD: %d_%d ==> Cpu%d\n", p.ParentId, p.Id, c.Id)
+	s.ModifyStatistics(func(s *Statistics) { s.TotalWaitTime += time.Since(p.CreatedAt) })
+	c.setCurrentProcess(p, true)
+	c.DirectQueue <- p
+}
+
+// Enqueue puts a process into the queue, it is lost if the queue is full
+func (s *Scheduler) Enqueue(p Process) {
+	if s.QueueCapacity > 0 && len(s.Queue) >= s.QueueCapacity {
+		s.Lose(p)
+		return
+	}
+	s.Queue = append(s.Queue, p)
+	s.ChangeQueueLength(1)
+	formatLog("SCHD: %d_%d ==> CpuQueue\n", p.ParentId, p.Id)
+}
+
+func (s *Scheduler) Lose(p Process) {
+	formatLog("SCHD: %d_%d is lost\n", p.ParentId, p.Id)
+	s.ModifyStatistics(func(s *Statistics) { s.LostProcesses++ })
+}
+
+func (s *Scheduler) Destroy(p Process) {
+	formatLog("SCHD: %d_%d is destroyed\n", p.ParentId, p.Id)
+	s.ModifyStatistics(func(s *Statistics) { s.DestroyedProcesses++ })
+}
+
+// dispatchQueued gives queued processes to free cpus in the order the policy picks them
+func (s *Scheduler) dispatchQueued() {
+	for _, c := range s.Cpus {
+		if len(s.Queue) == 0 {
+			return
+		}
+		if _, busy := c.GetCurrentProcess(); busy {
+			continue
+		}
+		i := s.Policy.Next(s, c)
+		if i < 0 {
+			continue
+		}
+		p := s.Queue[i]
+		s.Queue = append(s.Queue[:i], s.Queue[i+1:]...)
+		s.ChangeQueueLength(-1)
+		s.Dispatch(c, p)
+	}
+}
+
+func (s *Scheduler) idle() bool {
+	return len(s.Queue) == 0 && s.FreeCpuCount() == len(s.Cpus)
+}
+
+func (s *Scheduler) FreeCpuCount() int {
+	count := 0
+	for _, c := range s.Cpus {
+		if _, busy := c.GetCurrentProcess(); !busy {
+			count++
+		}
+	}
+	return count
 }
 
 func (s *Scheduler) Run() {
@@ -170,70 +269,39 @@ func (s *Scheduler) Run() {
 		noMoreProccessesChannel <- true
 	}()
 
-	pushToDirectQueue := func(c *Cpu, p Process) {
-		formatLog("SCHD: %d_%d ==> Cpu%d\n", p.ParentId, p.Id, c.Id)
-		c.DirectQueue <- p
-	}
-
-	scheduleProcess := func(p Process) {
-		switch p.ParentId {
-		case 1:
-			cpu1Process, cpu1Busy := s.Cpu1.GetCurrentProcess()
-			if !cpu1Busy {
-				pushToDirectQueue(s.Cpu1, p)
-				return
-			}
-			if cpu1Process.ParentId != 1 {
-				formatLog("SCHD: %d_%d is lost\n", p.ParentId, p.Id)
-				s.ModifyStatistics(func(s *Statistics) { s.LostProcesses++ })
-				return
-			}
-			_, cpu2Busy := s.Cpu2.GetCurrentProcess()
-			if !cpu2Busy {
-				pushToDirectQueue(s.Cpu2, p)
-				return
-			}
-			formatLog("SCHD: %d_%d is destroyed\n", p.ParentId, p.Id)
-			s.ModifyStatistics(func(s *Statistics) { s.DestroyedProcesses++ })
-		case 2:
-			_, cpu2Busy := s.Cpu2.GetCurrentProcess()
-			if cpu2Busy {
-				s.CpuQueue <- p
-				s.ChangeQueueLength(1)
-				formatLog("SCHD: %d_%d ==> CpuQueue\n", p.ParentId, p.Id)
-			} else {
-				pushToDirectQueue(s.Cpu2, p)
-			}
-		default:
-			formatLog("Process has invalid ParentId = %d\n", p.ParentId)
-		}
-	}
-
-	for {
+	generatorsDone := false
+	for !generatorsDone || !s.idle() {
 		select {
 		case p := <-s.SchedulerQueue:
-			scheduleProcess(p)
+			s.Policy.Schedule(s, p)
+		case c := <-s.Finished:
+			p, _ := c.GetCurrentProcess()
+			c.setCurrentProcess(p, false)
 		case <-noMoreProccessesChannel:
-			close(s.CpuQueue)
-			return
+			generatorsDone = true
 		}
+		s.dispatchQueued()
+	}
+
+	for _, c := range s.Cpus {
+		close(c.DirectQueue)
 	}
 }
 
 func main() {
-	g1p := flag.Int("g1p", 15, "number of processes for GEN1 to generate")
-	g1m := flag.Int("g1m", 50, "min GEN1 process generation time")
-	g1M := flag.Int("g1M", 300, "max GEN1 process generation time")
+	var generators generatorFlags
+	var cpus cpuFlags
+	var routes routeFlags
+	flag.Var(&generators, "gen", "generator as processes:min:max[:priority] of generation time, can be repeated")
+	flag.Var(&cpus, "cpu", "cpu as min:max processing time, can be repeated")
+	flag.Var(&routes, "route", "route of the lab policy as generator:cpu[,cpu...][:onBusy[:onForeign]], can be repeated. "+
+		"onBusy is one of queue, lose and destroy, onForeign is also next")
+	configPath := flag.String("config", "", "json file with Generators, Cpus and Routes, repeated flags replace what it sets")
 
-	g2p := flag.Int("g2p", 15, "number of processes for GEN1 to generate")
-	g2m := flag.Int("g2m", 50, "min GEN1 process generation time")
-	g2M := flag.Int("g2M", 300, "max GEN1 process generation time")
+	policyName := flag.String("policy", "lab", fmt.Sprintf("scheduling policy, one of %v", policyNames()))
+	queueCapacity := flag.Int("queue", 0, "capacity of the queue, processes that don't fit are lost (unlimited if 0)")
 
-	c1m := flag.Int("c1m", 60, "min CPU1 processing time")
-	c1M := flag.Int("c1M", 200, "max CPU1 processing time")
-
-	c2m := flag.Int("c2m", 30, "min CPU1 processing time")
-	c2M := flag.Int("c2M", 100, "max CPU1 processing time")
+	seed := flag.Int64("seed", 0, "seed for random number generators (taken from current time if 0)")
 
 	logOn := flag.Bool("log", false, "whether to log runtime info")
 	printHelp := flag.Bool("help", false, "print this message")
@@ -245,83 +313,110 @@ func main() {
 		os.Exit(0)
 	}
 
+	newPolicy, ok := policies[*policyName]
+	if !ok {
+		fmt.Printf("unknown policy '%s', available policies are %v\n", *policyName, policyNames())
+		os.Exit(1)
+	}
+
+	// routes of the default setup don't make sense for other generators and cpus
+	config := defaultConfig()
+	if len(*configPath) > 0 {
+		var err error
+		config, err = loadConfig(*configPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	} else if len(generators) > 0 || len(cpus) > 0 {
+		config.Routes = nil
+	}
+	if len(generators) > 0 {
+		config.Generators = generators
+	}
+	if len(cpus) > 0 {
+		config.Cpus = cpus
+	}
+	if len(routes) > 0 {
+		config.Routes = routes
+	}
+	err := config.validate()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	if !*logOn {
 		formatLog = func(s string, i ...interface{}) {}
 	}
 
-	stat := Statistics{
-		TotalProcesses: *g1p + *g2p,
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
 	}
+	generatorsSeed := deriveSeed(*seed, generatorSeedStream)
+
+	stat := Statistics{}
 
 	schedulerQueue := make(chan Process)
-	cpuQueue := make(chan Process, *g1p+*g2p)
+	finished := make(chan *Cpu, len(config.Cpus))
 	var genWg sync.WaitGroup
-	genWg.Add(2)
+	genWg.Add(len(config.Generators))
 	var cpuWg sync.WaitGroup
-	cpuWg.Add(2)
+	cpuWg.Add(len(config.Cpus))
 
-	gen1 := ProcessGenerator{
-		Id:             1,
-		SchedulerQueue: schedulerQueue,
-		Wg:             &genWg,
+	scheduler := Scheduler{
+		Statistics: &stat,
 
-		ProcessesToGenerate:      *g1p,
-		MinProcessGenerationTime: *g1m,
-		MaxProcessGenerationTime: *g1M,
-	}
+		Policy: newPolicy(),
+		Routes: make(map[int]Route, len(config.Routes)),
 
-	gen2 := ProcessGenerator{
-		Id:             2,
 		SchedulerQueue: schedulerQueue,
-		Wg:             &genWg,
-
-		ProcessesToGenerate:      *g2p,
-		MinProcessGenerationTime: *g2m,
-		MaxProcessGenerationTime: *g2M,
+		Finished:       finished,
+		QueueCapacity:  *queueCapacity,
+		GenWg:          &genWg,
 	}
 
-	cpu1 := Cpu{
-		Id:           1,
-		GeneralQueue: cpuQueue,
-		DirectQueue:  make(chan Process),
-		Wg:           &cpuWg,
-
-		Statistics: &stat,
-
-		MinProcessingTime: *c1m,
-		MaxProcessingTime: *c1M,
+	for i, g := range config.Generators {
+		stat.TotalProcesses += g.Processes
+		scheduler.Generators = append(scheduler.Generators, &ProcessGenerator{
+			Id:             i + 1,
+			SchedulerQueue: schedulerQueue,
+			Wg:             &genWg,
+
+			ProcessesToGenerate:      g.Processes,
+			MinProcessGenerationTime: g.Min,
+			MaxProcessGenerationTime: g.Max,
+			Priority:                 g.Priority,
+			Seed:                     deriveSeed(generatorsSeed, int64(i+1)),
+		})
 	}
 
-	cpu2 := Cpu{
-		Id:           2,
-		GeneralQueue: cpuQueue,
-		DirectQueue:  make(chan Process),
-		Wg:           &cpuWg,
-
-		Statistics: &stat,
+	for i, c := range config.Cpus {
+		scheduler.Cpus = append(scheduler.Cpus, &Cpu{
+			Id:          i + 1,
+			DirectQueue: make(chan Process),
+			Finished:    finished,
+			Wg:          &cpuWg,
 
-		MinProcessingTime: *c2m,
-		MaxProcessingTime: *c2M,
+			MinProcessingTime: c.Min,
+			MaxProcessingTime: c.Max,
+		})
 	}
 
-	scheduler := Scheduler{
-		Gen1: &gen1,
-		Gen2: &gen2,
-		Cpu1: &cpu1,
-		Cpu2: &cpu2,
-
-		Statistics: &stat,
-
-		SchedulerQueue: schedulerQueue,
-		CpuQueue:       cpuQueue,
-		GenWg:          &genWg,
+	for _, r := range config.Routes {
+		scheduler.Routes[r.Generator] = r
 	}
 
-	go cpu1.Run()
-	go cpu2.Run()
-	go gen1.Run()
-	go gen2.Run()
+	for _, c := range scheduler.Cpus {
+		go c.Run()
+	}
+	for _, g := range scheduler.Generators {
+		go g.Run()
+	}
 
+	fmt.Println("Seed:", *seed)
+	fmt.Println("Policy:", *policyName)
+	fmt.Printf("Generators: %d, CPUs: %d\n", len(scheduler.Generators), len(scheduler.Cpus))
 	fmt.Println("Running...")
 
 	scheduler.Run()
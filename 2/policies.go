@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sort"
+)
+
+// SchedulingPolicy decides what happens to processes coming from generators.
+// Schedule either dispatches a process to a free cpu, queues, loses or destroys it.
+// Next picks the index of a queued process to run on a cpu that became free,
+// it returns -1 to leave the cpu idle
+type SchedulingPolicy interface {
+	Schedule(s *Scheduler, p Process)
+	Next(s *Scheduler, c *Cpu) int
+}
+
+var policies = map[string]func() SchedulingPolicy{
+	"lab":         func() SchedulingPolicy { return labPolicy{} },
+	"fifo":        func() SchedulingPolicy { return fifoPolicy{} },
+	"round-robin": func() SchedulingPolicy { return &roundRobinPolicy{} },
+	"sjf":         func() SchedulingPolicy { return sjfPolicy{} },
+	"priority":    func() SchedulingPolicy { return priorityPolicy{} },
+}
+
+func policyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// labPolicy sends processes along the routes of their generators,
+// processes of generators without a route are scheduled as with fifoPolicy.
+// Queued processes are run by any cpu that becomes free
+type labPolicy struct{}
+
+func (labPolicy) Schedule(s *Scheduler, p Process) {
+	route, ok := s.Routes[p.ParentId]
+	if !ok {
+		fifoPolicy{}.Schedule(s, p)
+		return
+	}
+
+	first, firstBusy := s.Cpu(route.Cpus[0]).GetCurrentProcess()
+	if firstBusy && first.ParentId != p.ParentId && route.OnForeign != actionNext {
+		route.OnForeign.apply(s, p)
+		return
+	}
+	for _, id := range route.Cpus {
+		c := s.Cpu(id)
+		if _, busy := c.GetCurrentProcess(); !busy {
+			s.Dispatch(c, p)
+			return
+		}
+	}
+	route.OnBusy.apply(s, p)
+}
+
+func (labPolicy) Next(s *Scheduler, c *Cpu) int {
+	return 0
+}
+
+// fifoPolicy runs a process on the first free cpu or queues it,
+// queued processes are run in the order they came
+type fifoPolicy struct{}
+
+func (fifoPolicy) Schedule(s *Scheduler, p Process) {
+	if c := s.FreeCpu(0); c != nil {
+		s.Dispatch(c, p)
+		return
+	}
+	s.Enqueue(p)
+}
+
+func (fifoPolicy) Next(s *Scheduler, c *Cpu) int {
+	return 0
+}
+
+// roundRobinPolicy is fifoPolicy that starts looking for a free cpu
+// from the one after the cpu it used last
+type roundRobinPolicy struct {
+	next int
+}
+
+func (r *roundRobinPolicy) Schedule(s *Scheduler, p Process) {
+	c := s.FreeCpu(r.next)
+	if c == nil {
+		s.Enqueue(p)
+		return
+	}
+	s.Dispatch(c, p)
+
+	for i, cpu := range s.Cpus {
+		if cpu == c {
+			r.next = (i + 1) % len(s.Cpus)
+		}
+	}
+}
+
+func (r *roundRobinPolicy) Next(s *Scheduler, c *Cpu) int {
+	return 0
+}
+
+// sjfPolicy runs the shortest queued process first
+type sjfPolicy struct {
+	fifoPolicy
+}
+
+func (sjfPolicy) Next(s *Scheduler, c *Cpu) int {
+	next := 0
+	for i, p := range s.Queue {
+		if p.Size < s.Queue[next].Size {
+			next = i
+		}
+	}
+	return next
+}
+
+// priorityPolicy runs the queued process with the highest priority first,
+// processes of the same priority are run in the order they came
+type priorityPolicy struct {
+	fifoPolicy
+}
+
+func (priorityPolicy) Next(s *Scheduler, c *Cpu) int {
+	next := 0
+	for i, p := range s.Queue {
+		if p.Priority > s.Queue[next].Priority {
+			next = i
+		}
+	}
+	return next
+}
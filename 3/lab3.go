@@ -33,11 +33,20 @@ func ExecutePerSplitParallel(size int, splits int, f func(int, int)) {
 	wg.Wait()
 }
 
-func GenerateArray(size int) *[]int {
+// deriveSeed uses splitmix64 to turn one seed into independent per-split seeds
+func deriveSeed(seed int64, stream int64) int64 {
+	z := uint64(seed) + uint64(stream)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// every split gets a stream derived from its lower bound
+func GenerateArray(size int, seed int64) *[]int {
 	arr := make([]int, size)
 
 	ExecutePerSplitParallel(size, 10, func(lo, hi int) {
-		randSource := rand.NewSource(time.Now().UnixNano())
+		randSource := rand.NewSource(deriveSeed(seed, int64(lo)))
 		rand := rand.New(randSource)
 
 		for i := lo; i < hi; i++ {
@@ -117,6 +126,7 @@ func main() {
 
 	size := flag.Int("size", 1000, "size of an array")
 	iterationsPerAlgorithm := flag.Int("iterations", 1, "number of iterations to do per algorithm when timing it")
+	seed := flag.Int64("seed", 0, "seed for random number generators (taken from current time if 0)")
 
 	printHelp := flag.Bool("help", false, "print this message")
 
@@ -149,8 +159,12 @@ func main() {
 		},
 	}
 
-	fmt.Println("Generating an array...")
-	arr := GenerateArray(*size)
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	fmt.Printf("Generating an array with seed %d...\n", *seed)
+	arr := GenerateArray(*size, *seed)
 
 	for _, algo := range algorithms {
 		if !algo.Enabled {
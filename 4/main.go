@@ -19,8 +19,14 @@ import (
 func main() {
 	size := flag.Int("size", 10, "size of the arrays")
 	printArrays := flag.Bool("p", true, "whether to print array bodies")
+	seed := flag.Int64("seed", 0, "seed for random number generators (taken from current time if 0)")
 	flag.Parse()
 
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Println("seed:", *seed)
+
 	getLogger := func(arrNum string) func(string, []int) {
 		if *printArrays {
 			return func(s string, arr []int) {
@@ -35,15 +41,15 @@ func main() {
 	}
 
 	arr1Promise := ProcessArrAsync(
-		GenerateArrayAsync(*size),
+		GenerateArrayAsync(*size, deriveSeed(*seed, 1)),
 		ProcessArr1,
 		getLogger("1"))
 	arr2Promise := ProcessArrAsync(
-		GenerateArrayAsync(*size),
+		GenerateArrayAsync(*size, deriveSeed(*seed, 2)),
 		ProcessArr2,
 		getLogger("2"))
 	arr3Promise := ProcessArrAsync(
-		GenerateArrayAsync(*size),
+		GenerateArrayAsync(*size, deriveSeed(*seed, 3)),
 		ProcessArr3,
 		getLogger("3"))
 
@@ -121,14 +127,22 @@ func ProcessArr3(arr []int) []int {
 	return arrOut
 }
 
-func GenerateArrayAsync(size int) async.Promise[[]int] {
+func GenerateArrayAsync(size int, seed int64) async.Promise[[]int] {
 	return async.RunAsync(func() []int {
-		return GenerateArray(size)
+		return GenerateArray(size, seed)
 	})
 }
 
-func GenerateArray(size int) []int {
-	randSource := rand.NewSource(time.Now().UnixNano())
+// deriveSeed gives every generated array its own seed (splitmix64 mixing)
+func deriveSeed(seed int64, stream int64) int64 {
+	z := uint64(seed) + uint64(stream)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+func GenerateArray(size int, seed int64) []int {
+	randSource := rand.NewSource(seed)
 	rand := rand.New(randSource)
 
 	arr := make([]int, 0, size)